@@ -56,9 +56,8 @@ func displayNetworkInfo(mm *mmcli.ModemManager) {
 
 func displayPortInfo(mm *mmcli.ModemManager) {
 	fmt.Println("Available Ports:")
-	ports := mm.GetAllPorts()
-	for portType, portName := range ports {
-		fmt.Printf("- %s: %s\n", strings.ToUpper(portType), portName)
+	for _, port := range mm.Ports() {
+		fmt.Printf("- %s: %s\n", strings.ToUpper(port.Type.String()), port.Name)
 	}
 }
 
@@ -90,6 +89,8 @@ func main() {
 
 	fmt.Printf("Monitoring modem %s...\n", id)
 
+	// The full status is printed once, afterwards only the changes
+	var last *mmcli.ModemManager
 	for {
 		// Get modem details
 		mm, err := mmcli.GetModemDetails(id)
@@ -99,6 +100,21 @@ func main() {
 			continue
 		}
 
+		if last != nil {
+			// last only moves on with reported changes, so that small
+			// signal changes add up until they cross the threshold
+			if changes := mmcli.Diff(last, mm); len(changes) > 0 {
+				last = mm
+				fmt.Printf("\n=== Changes (%s) ===\n", time.Now().Format("15:04:05"))
+				for _, change := range changes {
+					fmt.Println(change)
+				}
+			}
+			time.Sleep(*interval)
+			continue
+		}
+		last = mm
+
 		// Clear screen
 		fmt.Print("\033[H\033[2J")
 
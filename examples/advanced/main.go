@@ -50,9 +50,8 @@ func writeNetworkDetails(w *tabwriter.Writer, mm *mmcli.ModemManager) {
 // writePortDetails writes detailed port information
 func writePortDetails(w *tabwriter.Writer, mm *mmcli.ModemManager) {
 	fmt.Fprintln(w, "=== Port Details ===\t\t")
-	ports := mm.GetAllPorts()
-	for portType, portName := range ports {
-		fmt.Fprintf(w, "%s Port:\t%s\t\n", strings.ToUpper(portType), portName)
+	for _, port := range mm.Ports() {
+		fmt.Fprintf(w, "%s Port:\t%s\t\n", strings.ToUpper(port.Type.String()), port.Name)
 	}
 	fmt.Fprintln(w, "\t\t")
 }
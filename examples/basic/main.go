@@ -54,9 +54,11 @@ func main() {
 
 		// Check SIM status
 		if mm.IsSimLocked() {
-			fmt.Println("\nSIM Status: Locked")
-			retries := mm.RemainingUnlockRetries("sim-pin")
-			fmt.Printf("Remaining PIN attempts: %d\n", retries)
+			lock := mm.UnlockRequired()
+			fmt.Printf("\nSIM Status: Locked (%s)\n", lock)
+			if retries, known := mm.UnlockRetries().Get(lock); known {
+				fmt.Printf("Remaining attempts: %d\n", retries)
+			}
 		} else {
 			fmt.Println("\nSIM Status: Unlocked")
 		}
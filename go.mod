@@ -1,3 +1,5 @@
 module github.com/rescoot/go-mmcli
 
-go 1.20
+go 1.21
+
+require github.com/godbus/dbus/v5 v5.1.0
@@ -1,9 +1,10 @@
 package mmcli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"strconv"
 	"strings"
 )
 
@@ -13,6 +14,10 @@ type ModemList struct {
 
 type ModemManager struct {
 	Modem Modem `json:"modem"`
+
+	// Unknown holds the values mmcli printed that the structs do not model,
+	// keyed by their path as printed by mmcli -K, e.g. "modem.generic.foo"
+	Unknown map[string]json.RawMessage `json:"-"`
 }
 
 type Modem struct {
@@ -20,16 +25,24 @@ type Modem struct {
 	CDMA     CDMA             `json:"cdma"`
 	DBusPath string           `json:"dbus-path"`
 	Generic  ModemGenericInfo `json:"generic"`
+
+	// Only present in the output of the queries printing them, e.g. --signal-get
+	Signal   *SignalInfo    `json:"signal,omitempty"`
+	Firmware *FirmwareInfo  `json:"firmware,omitempty"`
+	Location *ModemLocation `json:"location,omitempty"`
 }
 
 type ThreeGPP struct {
-	EnabledLocks      []string `json:"enabled-locks"`
-	EPS               EPSInfo  `json:"eps"`
-	IMEI              string   `json:"imei"`
-	OperatorCode      string   `json:"operator-code"`
-	OperatorName      string   `json:"operator-name"`
-	PCO               string   `json:"pco"`
-	RegistrationState string   `json:"registration-state"`
+	FiveGNR            FiveGNR          `json:"5gnr"`
+	EnabledLocks       []string         `json:"enabled-locks"`
+	EPS                EPSInfo          `json:"eps"`
+	IMEI               string           `json:"imei"`
+	NetworkRejection   NetworkRejection `json:"network-rejection"`
+	OperatorCode       string           `json:"operator-code"`
+	OperatorName       string           `json:"operator-name"`
+	PacketServiceState string           `json:"packet-service-state"`
+	PCO                string           `json:"pco"`
+	RegistrationState  string           `json:"registration-state"`
 }
 
 // EPSInfo contains EPS (Evolved Packet System) information
@@ -62,40 +75,56 @@ type CDMA struct {
 }
 
 type ModemGenericInfo struct {
-	AccessTechnologies    []string      `json:"access-technologies"`
-	Bearers               []string      `json:"bearers"`
-	CarrierConfiguration  string        `json:"carrier-configuration"`
-	CurrentBands          []string      `json:"current-bands"`
-	CurrentCapabilities   []string      `json:"current-capabilities"`
-	CurrentModes          string        `json:"current-modes"`
-	Device                string        `json:"device"`
-	DeviceIdentifier      string        `json:"device-identifier"`
-	Drivers               []string      `json:"drivers"`
-	EquipmentIdentifier   string        `json:"equipment-identifier"`
-	HardwareRevision      string        `json:"hardware-revision"`
-	Manufacturer          string        `json:"manufacturer"`
-	Model                 string        `json:"model"`
-	OwnNumbers            []string      `json:"own-numbers"`
-	Plugin                string        `json:"plugin"`
-	Ports                 []string      `json:"ports"`
-	PowerState            string        `json:"power-state"`
-	PrimaryPort           string        `json:"primary-port"`
-	Revision              string        `json:"revision"`
-	SignalQuality         SignalQuality `json:"signal-quality"`
-	SIM                   string        `json:"sim"`
-	State                 string        `json:"state"`
-	StateFailedReason     string        `json:"state-failed-reason"`
-	SupportedBands        []string      `json:"supported-bands"`
-	SupportedCapabilities []string      `json:"supported-capabilities"`
-	SupportedIPFamilies   []string      `json:"supported-ip-families"`
-	SupportedModes        []string      `json:"supported-modes"`
-	UnlockRequired        string        `json:"unlock-required"`
-	UnlockRetries         []string      `json:"unlock-retries"`
+	AccessTechnologies           []string      `json:"access-technologies"`
+	Bearers                      []string      `json:"bearers"`
+	CarrierConfiguration         string        `json:"carrier-configuration"`
+	CarrierConfigurationRevision string        `json:"carrier-configuration-revision"`
+	CurrentBands                 []string      `json:"current-bands"`
+	CurrentCapabilities          []string      `json:"current-capabilities"`
+	CurrentModes                 string        `json:"current-modes"`
+	Device                       string        `json:"device"`
+	DeviceIdentifier             string        `json:"device-identifier"`
+	Drivers                      []string      `json:"drivers"`
+	EquipmentIdentifier          string        `json:"equipment-identifier"`
+	HardwareRevision             string        `json:"hardware-revision"`
+	Manufacturer                 string        `json:"manufacturer"`
+	MaxActiveBearers             string        `json:"max-active-bearers"`
+	MaxActiveMultiplexedBearers  string        `json:"max-active-multiplexed-bearers"`
+	MaxBearers                   string        `json:"max-bearers"`
+	Model                        string        `json:"model"`
+	OwnNumbers                   []string      `json:"own-numbers"`
+	Plugin                       string        `json:"plugin"`
+	Ports                        []string      `json:"ports"`
+	PowerState                   string        `json:"power-state"`
+	PrimaryPort                  string        `json:"primary-port"`
+	PrimarySIMSlot               string        `json:"primary-sim-slot"`
+	Revision                     string        `json:"revision"`
+	SignalQuality                SignalQuality `json:"signal-quality"`
+	SIM                          string        `json:"sim"`
+	SIMSlots                     []string      `json:"sim-slots"`
+	State                        string        `json:"state"`
+	StateFailedReason            string        `json:"state-failed-reason"`
+	SupportedBands               []string      `json:"supported-bands"`
+	SupportedCapabilities        []string      `json:"supported-capabilities"`
+	SupportedIPFamilies          []string      `json:"supported-ip-families"`
+	SupportedModes               []string      `json:"supported-modes"`
+	UnlockRequired               string        `json:"unlock-required"`
+	UnlockRetries                []string      `json:"unlock-retries"`
+
+	// Typed values decoded from the strings above, nil if unknown
+	MaxActiveBearersCount            *int `json:"-"`
+	MaxActiveMultiplexedBearersCount *int `json:"-"`
+	MaxBearersCount                  *int `json:"-"`
+	PrimarySIMSlotNumber             *int `json:"-"` // numbered from 1, nil on single-SIM modems
 }
 
 type SignalQuality struct {
 	Recent string `json:"recent"`
 	Value  string `json:"value"`
+
+	// Typed values decoded from the strings above
+	Percent  *int `json:"-"` // nil if unknown
+	IsRecent bool `json:"-"`
 }
 
 type SIMInfo struct {
@@ -111,17 +140,30 @@ type SIMProperties struct {
 	IMSI             string   `json:"imsi"`
 	OperatorCode     string   `json:"operator-code"`
 	OperatorName     string   `json:"operator-name"`
+
+	// Typed values decoded from the strings above
+	IsActive bool `json:"-"`
 }
 
 // ListModems returns a list of all available modems with their IDs
 func ListModems() ([]string, error) {
-	out, err := exec.Command("mmcli", "-J", "-L").Output()
+	return ListModemsContext(context.Background())
+}
+
+// ListModemsContext is like ListModems but uses ctx to bound the mmcli invocation
+func ListModemsContext(ctx context.Context) ([]string, error) {
+	return DefaultClient.ListModems(ctx)
+}
+
+// ListModems returns a list of all available modems with their IDs
+func (c *Client) ListModems(ctx context.Context) ([]string, error) {
+	out, err := c.run(ctx, c.output(), "-L")
 	if err != nil {
 		return nil, fmt.Errorf("mmcli list error: %w", err)
 	}
 
 	var list ModemList
-	if err := json.Unmarshal(out, &list); err != nil {
+	if err := c.unmarshal(out, &list); err != nil {
 		return nil, fmt.Errorf("failed to parse modem list: %w", err)
 	}
 
@@ -130,18 +172,28 @@ func ListModems() ([]string, error) {
 
 // GetModemIDs returns a list of numeric modem IDs
 func GetModemIDs() ([]string, error) {
-	modems, err := ListModems()
+	return GetModemIDsContext(context.Background())
+}
+
+// GetModemIDsContext is like GetModemIDs but uses ctx to bound the mmcli invocation
+func GetModemIDsContext(ctx context.Context) ([]string, error) {
+	return DefaultClient.GetModemIDs(ctx)
+}
+
+// GetModemIDs returns a list of numeric modem IDs
+func (c *Client) GetModemIDs(ctx context.Context) ([]string, error) {
+	modems, err := c.ListModems(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	ids := make([]string, len(modems))
 	for i, path := range modems {
-		parts := strings.Split(path, "/")
-		if len(parts) < 6 {
-			return nil, fmt.Errorf("invalid modem path format: %s", path)
+		index, err := ModemIndexFromPath(path)
+		if err != nil {
+			return nil, err
 		}
-		ids[i] = parts[5]
+		ids[i] = strconv.Itoa(index)
 	}
 
 	return ids, nil
@@ -149,7 +201,17 @@ func GetModemIDs() ([]string, error) {
 
 // GetFirstModemID returns the ID of the first available modem
 func GetFirstModemID() (string, error) {
-	ids, err := GetModemIDs()
+	return GetFirstModemIDContext(context.Background())
+}
+
+// GetFirstModemIDContext is like GetFirstModemID but uses ctx to bound the mmcli invocation
+func GetFirstModemIDContext(ctx context.Context) (string, error) {
+	return DefaultClient.GetFirstModemID(ctx)
+}
+
+// GetFirstModemID returns the ID of the first available modem
+func (c *Client) GetFirstModemID(ctx context.Context) (string, error) {
+	ids, err := c.GetModemIDs(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -163,16 +225,39 @@ func GetFirstModemID() (string, error) {
 
 // GetModemDetails returns details for a specific modem by ID
 func GetModemDetails(modemID string) (*ModemManager, error) {
-	out, err := exec.Command("mmcli", "-m", modemID, "-J").Output()
+	return GetModemDetailsContext(context.Background(), modemID)
+}
+
+// GetModemDetailsContext is like GetModemDetails but uses ctx to bound the mmcli invocation
+func GetModemDetailsContext(ctx context.Context, modemID string) (*ModemManager, error) {
+	return DefaultClient.GetModemDetails(ctx, modemID)
+}
+
+// GetModemDetails returns details for a specific modem by ID
+func (c *Client) GetModemDetails(ctx context.Context, modemID string) (*ModemManager, error) {
+	out, err := c.run(ctx, "-m", modemID, c.output())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get modem details: %w", err)
 	}
 
-	return Parse(out)
+	var mm ModemManager
+	if err := c.unmarshal(out, &mm); err != nil {
+		return nil, fmt.Errorf("failed to parse mmcli output: %w", err)
+	}
+	return &mm, nil
 }
 
 func ResetModem(modemID string) (bool, error) {
-	_, err := exec.Command("mmcli", "-m", modemID, "--reset").Output()
+	return ResetModemContext(context.Background(), modemID)
+}
+
+// ResetModemContext is like ResetModem but uses ctx to bound the mmcli invocation
+func ResetModemContext(ctx context.Context, modemID string) (bool, error) {
+	return DefaultClient.ResetModem(ctx, modemID)
+}
+
+func (c *Client) ResetModem(ctx context.Context, modemID string) (bool, error) {
+	_, err := c.run(ctx, "-m", modemID, "--reset")
 	if err != nil {
 		return false, fmt.Errorf("failed to reset modem: %w", err)
 	}
@@ -181,7 +266,16 @@ func ResetModem(modemID string) (bool, error) {
 }
 
 func GetSIMInfo(simID string) (*SIMInfo, error) {
-	out, err := exec.Command("mmcli", "-i", simID, "-J").Output()
+	return GetSIMInfoContext(context.Background(), simID)
+}
+
+// GetSIMInfoContext is like GetSIMInfo but uses ctx to bound the mmcli invocation
+func GetSIMInfoContext(ctx context.Context, simID string) (*SIMInfo, error) {
+	return DefaultClient.GetSIMInfo(ctx, simID)
+}
+
+func (c *Client) GetSIMInfo(ctx context.Context, simID string) (*SIMInfo, error) {
+	out, err := c.run(ctx, "-i", simID, c.output())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get SIM info: %w", err)
 	}
@@ -190,13 +284,34 @@ func GetSIMInfo(simID string) (*SIMInfo, error) {
 		SIM SIMInfo `json:"sim"`
 	}
 
-	if err := json.Unmarshal(out, &response); err != nil {
+	if err := c.unmarshal(out, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse SIM info: %w", err)
 	}
 
 	return &response.SIM, nil
 }
 
+// SetPrimarySIMSlot switches a multi-SIM modem to the given SIM slot (numbered from 1).
+// Requires mmcli 1.16 or newer.
+func SetPrimarySIMSlot(modemID string, slot int) error {
+	return SetPrimarySIMSlotContext(context.Background(), modemID, slot)
+}
+
+// SetPrimarySIMSlotContext is like SetPrimarySIMSlot but uses ctx to bound the mmcli invocation
+func SetPrimarySIMSlotContext(ctx context.Context, modemID string, slot int) error {
+	return DefaultClient.SetPrimarySIMSlot(ctx, modemID, slot)
+}
+
+// SetPrimarySIMSlot switches a multi-SIM modem to the given SIM slot (numbered from 1)
+func (c *Client) SetPrimarySIMSlot(ctx context.Context, modemID string, slot int) error {
+	_, err := c.run(ctx, "-m", modemID, fmt.Sprintf("--set-primary-sim-slot=%d", slot))
+	if err != nil {
+		return fmt.Errorf("failed to set primary SIM slot: %w", err)
+	}
+
+	return nil
+}
+
 // Parse parses mmcli JSON output into a ModemManager struct
 func Parse(data []byte) (*ModemManager, error) {
 	var mm ModemManager
@@ -210,7 +325,7 @@ func Parse(data []byte) (*ModemManager, error) {
 
 // IsConnected returns true if the modem is in connected state
 func (mm *ModemManager) IsConnected() bool {
-	return mm.Modem.Generic.State == "connected"
+	return mm.State().IsConnected()
 }
 
 // SignalStrength returns the signal strength as an integer percentage
@@ -223,38 +338,35 @@ func (mm *ModemManager) SignalStrength() (int, error) {
 	return strength, nil
 }
 
-// GetPortByType returns the port name for a given type (qmi, at, net, etc)
+// GetPortByType returns the name of the first port of a given type (qmi, at, net, etc).
+// Types this package does not know are matched against the port list as printed.
+// Use PortsByType to get all ports of a type.
 func (mm *ModemManager) GetPortByType(portType string) string {
-	portType = strings.ToLower(portType)
-	for _, port := range mm.Modem.Generic.Ports {
-		if strings.Contains(strings.ToLower(port), "("+portType+")") {
-			parts := strings.Split(port, " ")
-			if len(parts) > 0 {
-				return parts[0]
+	t, err := ParsePortType(portType)
+	if err != nil {
+		portType = "(" + strings.ToLower(strings.TrimSpace(portType)) + ")"
+		for _, port := range mm.Modem.Generic.Ports {
+			if strings.Contains(strings.ToLower(port), portType) {
+				return strings.Fields(port)[0]
 			}
 		}
+		return ""
+	}
+	if ports := mm.PortsByType(t); len(ports) > 0 {
+		return ports[0].Name
 	}
 	return ""
 }
 
 // IsSimLocked returns true if the modem requires a SIM PIN
 func (mm *ModemManager) IsSimLocked() bool {
-	return strings.HasPrefix(mm.Modem.Generic.UnlockRequired, "sim-")
+	return mm.UnlockRequired().IsSIM()
 }
 
-// GetCurrentAccessTechnology returns the current access technology (2G/3G/4G)
+// GetCurrentAccessTechnology returns the generation of the current access
+// technology (2G, 3G, 4G, 5G NSA, 5G SA, LTE-M or NB-IoT)
 func (mm *ModemManager) GetCurrentAccessTechnology() string {
-	for _, tech := range mm.Modem.Generic.AccessTechnologies {
-		switch strings.ToLower(tech) {
-		case "lte":
-			return "4G"
-		case "umts", "hspa", "hspa+":
-			return "3G"
-		case "gsm", "gprs", "edge":
-			return "2G"
-		}
-	}
-	return "Unknown"
+	return mm.AccessTechnologies().Generation().String()
 }
 
 // GetOperatorInfo returns the operator name and code
@@ -262,16 +374,16 @@ func (mm *ModemManager) GetOperatorInfo() (name string, code string) {
 	return mm.Modem.ThreeGPP.OperatorName, mm.Modem.ThreeGPP.OperatorCode
 }
 
-// RemainingUnlockRetries returns the remaining unlock attempts for a given lock type
+// RemainingUnlockRetries returns the remaining unlock attempts for a given lock type.
+// It also returns 0 if the modem did not report retries for the lock, use
+// UnlockRetries().Get to tell both cases apart.
 func (mm *ModemManager) RemainingUnlockRetries(lockType string) int {
-	for _, retry := range mm.Modem.Generic.UnlockRetries {
-		if strings.HasPrefix(retry, lockType) {
-			var attempts int
-			fmt.Sscanf(retry, "%s (%d)", &lockType, &attempts)
-			return attempts
-		}
+	lock, err := ParseLock(lockType)
+	if err != nil {
+		return 0
 	}
-	return 0
+	attempts, _ := mm.UnlockRetries().Get(lock)
+	return attempts
 }
 
 // IsIPv6Supported returns true if the modem supports IPv6
@@ -284,15 +396,13 @@ func (mm *ModemManager) IsIPv6Supported() bool {
 	return false
 }
 
-// GetAllPorts returns a map of port types to port names
+// GetAllPorts returns a map of port types to port names.
+//
+// Deprecated: only one port per type is kept, use Ports instead.
 func (mm *ModemManager) GetAllPorts() map[string]string {
 	ports := make(map[string]string)
-	for _, port := range mm.Modem.Generic.Ports {
-		parts := strings.Split(port, " ")
-		if len(parts) >= 2 {
-			portType := strings.Trim(parts[1], "()")
-			ports[portType] = parts[0]
-		}
+	for _, port := range mm.Ports() {
+		ports[port.Type.String()] = port.Name
 	}
 	return ports
 }
@@ -0,0 +1,34 @@
+package mmcli
+
+import "context"
+
+// Backend answers modem queries and performs modem operations.
+//
+// Client implements Backend by running mmcli; the mmdbus package provides an
+// implementation that talks to ModemManager over D-Bus directly. Code written
+// against Backend works with either.
+type Backend interface {
+	ListModems(ctx context.Context) ([]string, error)
+	GetModemDetails(ctx context.Context, modemID string) (*ModemManager, error)
+	ResetModem(ctx context.Context, modemID string) (bool, error)
+	GetSIMInfo(ctx context.Context, simID string) (*SIMInfo, error)
+
+	Connect(ctx context.Context, modemID string, settings ConnectSettings) error
+	Disconnect(ctx context.Context, modemID string) error
+
+	GetLocationStatus(ctx context.Context, modemID string) (*LocationStatus, error)
+	GetLocation(ctx context.Context, modemID string) (*LocationInfo, error)
+	EnableLocationGathering(ctx context.Context, modemID string, method string) error
+	DisableLocationGathering(ctx context.Context, modemID string, method string) error
+
+	GetMessagingStatus(ctx context.Context, modemID string) (*MessagingStatus, error)
+	ListSMS(ctx context.Context, modemID string) ([]string, error)
+	GetSMSInfo(ctx context.Context, smsID string) (*SMSInfo, error)
+	CreateSMS(ctx context.Context, modemID string, settings SMSCreateSettings) (string, error)
+	SendSMS(ctx context.Context, smsID string) error
+	DeleteSMS(ctx context.Context, modemID string, smsID string) error
+
+	GetNetworkTime(ctx context.Context, modemID string) (*TimeInfo, error)
+}
+
+var _ Backend = (*Client)(nil)
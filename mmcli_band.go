@@ -0,0 +1,340 @@
+package mmcli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Band is a radio band. The values match MMModemBand, e.g. eutran-3 is 33.
+type Band uint32
+
+// Special bands
+const (
+	BandUnknown Band = 0
+	BandAny     Band = 256
+)
+
+// legacyBands holds the names of MMModemBand values 1 to 20
+var legacyBands = [20]string{
+	"egsm", "dcs", "pcs", "g850",
+	"utran-1", "utran-3", "utran-4", "utran-6", "utran-5", "utran-8", "utran-9", "utran-2", "utran-7",
+	"g450", "g480", "g750", "g380", "g410", "g710", "g810",
+}
+
+// UTRANBand returns the UMTS band with the given number, e.g. 1 for utran-1
+func UTRANBand(n int) Band {
+	b, _ := ParseBand(fmt.Sprintf("utran-%d", n))
+	return b
+}
+
+// EUTRANBand returns the LTE band with the given number, e.g. 3 for eutran-3
+func EUTRANBand(n int) Band {
+	b, _ := ParseBand(fmt.Sprintf("eutran-%d", n))
+	return b
+}
+
+// NGRANBand returns the 5G NR band with the given number, e.g. 78 for ngran-78
+func NGRANBand(n int) Band {
+	b, _ := ParseBand(fmt.Sprintf("ngran-%d", n))
+	return b
+}
+
+// ParseBand parses a band name as printed by mmcli, e.g. "eutran-3",
+// "utran-1", "ngran-78" or "egsm". The short forms used in carrier band lists,
+// e.g. "B3" for LTE and "n78" for 5G NR, are accepted as well.
+func ParseBand(s string) (Band, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	switch name {
+	case "unknown":
+		return BandUnknown, nil
+	case "any":
+		return BandAny, nil
+	}
+	for i, legacy := range legacyBands {
+		if legacy == name {
+			return Band(i + 1), nil
+		}
+	}
+
+	prefix, number := splitBandName(name)
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return BandUnknown, fmt.Errorf("unknown band %q", s)
+	}
+
+	switch {
+	case (prefix == "eutran-" || prefix == "b") && n >= 1 && n <= 85:
+		return Band(30 + n), nil
+	case (prefix == "ngran-" || prefix == "n") && n >= 1 && n <= 299:
+		return Band(300 + n), nil
+	case prefix == "utran-" && n >= 10 && n <= 32:
+		return Band(200 + n), nil
+	case prefix == "cdma-bc" && n >= 0 && n <= 19:
+		return Band(128 + n), nil
+	}
+	return BandUnknown, fmt.Errorf("unknown band %q", s)
+}
+
+// splitBandName splits a band name into its prefix and trailing number
+func splitBandName(name string) (prefix, number string) {
+	i := len(name)
+	for i > 0 && name[i-1] >= '0' && name[i-1] <= '9' {
+		i--
+	}
+	return name[:i], name[i:]
+}
+
+// String returns the band as printed by mmcli, e.g. "eutran-3"
+func (b Band) String() string {
+	switch {
+	case b >= 1 && b <= 20:
+		return legacyBands[b-1]
+	case b >= 31 && b <= 115:
+		return fmt.Sprintf("eutran-%d", b-30)
+	case b >= 128 && b <= 147:
+		return fmt.Sprintf("cdma-bc%d", b-128)
+	case b >= 210 && b <= 232:
+		return fmt.Sprintf("utran-%d", b-200)
+	case b == BandAny:
+		return "any"
+	case b >= 301 && b <= 599:
+		return fmt.Sprintf("ngran-%d", b-300)
+	}
+	return "unknown"
+}
+
+// Technology returns the radio technology the band belongs to
+func (b Band) Technology() BandTechnology {
+	name := b.String()
+	switch {
+	case strings.HasPrefix(name, "utran-"):
+		return BandTechnologyUTRAN
+	case strings.HasPrefix(name, "eutran-"):
+		return BandTechnologyEUTRAN
+	case strings.HasPrefix(name, "ngran-"):
+		return BandTechnologyNGRAN
+	case strings.HasPrefix(name, "cdma-"):
+		return BandTechnologyCDMA
+	case b >= 1 && b <= 20:
+		return BandTechnologyGSM
+	}
+	return BandTechnologyUnknown
+}
+
+// Number returns the 3GPP band number, e.g. 3 for eutran-3, or the band class
+// for CDMA bands. GSM bands are not numbered and return 0.
+func (b Band) Number() int {
+	if b.Technology() == BandTechnologyGSM {
+		return 0
+	}
+	_, number := splitBandName(b.String())
+	n, _ := strconv.Atoi(number)
+	return n
+}
+
+// Duplex returns the duplex mode of the band, or DuplexUnknown for bands
+// without frequency metadata
+func (b Band) Duplex() Duplex {
+	return b.info().duplex
+}
+
+// Uplink returns the nominal uplink frequency range. It is zero for
+// supplemental downlink bands and for bands without frequency metadata.
+func (b Band) Uplink() FrequencyRange {
+	return b.info().uplink
+}
+
+// Downlink returns the nominal downlink frequency range. It is zero for
+// supplemental uplink bands and for bands without frequency metadata.
+func (b Band) Downlink() FrequencyRange {
+	return b.info().downlink
+}
+
+func (b Band) info() bandInfo {
+	switch b.Technology() {
+	case BandTechnologyGSM:
+		return gsmBands[b.String()]
+	case BandTechnologyUTRAN:
+		return utranBands[b.Number()]
+	case BandTechnologyEUTRAN:
+		return eutranBands[b.Number()]
+	case BandTechnologyNGRAN:
+		return ngranBands[b.Number()]
+	case BandTechnologyCDMA:
+		return cdmaBands[b.Number()]
+	}
+	return bandInfo{}
+}
+
+// BandTechnology is the radio technology of a band
+type BandTechnology int
+
+// Band technologies
+const (
+	BandTechnologyUnknown BandTechnology = iota
+	BandTechnologyGSM
+	BandTechnologyUTRAN
+	BandTechnologyEUTRAN
+	BandTechnologyNGRAN
+	BandTechnologyCDMA
+)
+
+// String returns the technology, e.g. "eutran"
+func (t BandTechnology) String() string {
+	switch t {
+	case BandTechnologyGSM:
+		return "gsm"
+	case BandTechnologyUTRAN:
+		return "utran"
+	case BandTechnologyEUTRAN:
+		return "eutran"
+	case BandTechnologyNGRAN:
+		return "ngran"
+	case BandTechnologyCDMA:
+		return "cdma"
+	}
+	return "unknown"
+}
+
+// Duplex is the duplex mode of a band
+type Duplex int
+
+// Duplex modes
+const (
+	DuplexUnknown Duplex = iota
+	DuplexFDD            // frequency division, separate uplink and downlink
+	DuplexTDD            // time division, uplink and downlink share the range
+	DuplexSDL            // supplemental downlink only
+	DuplexSUL            // supplemental uplink only
+)
+
+// String returns the duplex mode, e.g. "FDD"
+func (d Duplex) String() string {
+	switch d {
+	case DuplexFDD:
+		return "FDD"
+	case DuplexTDD:
+		return "TDD"
+	case DuplexSDL:
+		return "SDL"
+	case DuplexSUL:
+		return "SUL"
+	}
+	return "unknown"
+}
+
+// FrequencyRange is a frequency range in MHz
+type FrequencyRange struct {
+	Low  float64
+	High float64
+}
+
+// IsZero reports whether the range is unknown or not applicable
+func (r FrequencyRange) IsZero() bool {
+	return r.Low == 0 && r.High == 0
+}
+
+// Contains reports whether mhz lies within the range
+func (r FrequencyRange) Contains(mhz float64) bool {
+	return !r.IsZero() && mhz >= r.Low && mhz <= r.High
+}
+
+// String returns the range, e.g. "1710-1785 MHz"
+func (r FrequencyRange) String() string {
+	if r.IsZero() {
+		return "--"
+	}
+	return fmt.Sprintf("%s-%s MHz", strconv.FormatFloat(r.Low, 'f', -1, 64), strconv.FormatFloat(r.High, 'f', -1, 64))
+}
+
+// Bands is a list of bands with set operations. The operations keep the order
+// of the receiver and drop duplicates.
+type Bands []Band
+
+// ParseBands parses band names as printed by mmcli. Unknown names are
+// reported in the error, the returned list holds all known bands.
+func ParseBands(names []string) (Bands, error) {
+	var bands Bands
+	var unknown []string
+	for _, name := range names {
+		if name == "" || name == "--" {
+			continue
+		}
+		b, err := ParseBand(name)
+		if err != nil {
+			unknown = append(unknown, name)
+			continue
+		}
+		bands = append(bands, b)
+	}
+	if len(unknown) > 0 {
+		return bands, fmt.Errorf("unknown bands %q", unknown)
+	}
+	return bands, nil
+}
+
+// Contains reports whether the list holds the band
+func (bs Bands) Contains(band Band) bool {
+	for _, b := range bs {
+		if b == band {
+			return true
+		}
+	}
+	return false
+}
+
+// Intersect returns the bands that are in both lists
+func (bs Bands) Intersect(other Bands) Bands {
+	return bs.filter(func(b Band) bool { return other.Contains(b) })
+}
+
+// Difference returns the bands that are not in other
+func (bs Bands) Difference(other Bands) Bands {
+	return bs.filter(func(b Band) bool { return !other.Contains(b) })
+}
+
+// Union returns the bands that are in either list
+func (bs Bands) Union(other Bands) Bands {
+	return append(append(Bands{}, bs...), other...).filter(func(Band) bool { return true })
+}
+
+// Technology returns the bands of the given technology
+func (bs Bands) Technology(tech BandTechnology) Bands {
+	return bs.filter(func(b Band) bool { return b.Technology() == tech })
+}
+
+// Strings returns the bands as printed by mmcli
+func (bs Bands) Strings() []string {
+	var names []string
+	for _, b := range bs {
+		names = append(names, b.String())
+	}
+	return names
+}
+
+func (bs Bands) filter(keep func(Band) bool) Bands {
+	var out Bands
+	seen := make(map[Band]bool)
+	for _, b := range bs {
+		if !seen[b] && keep(b) {
+			out = append(out, b)
+		}
+		seen[b] = true
+	}
+	return out
+}
+
+// CurrentBands returns the bands the modem is currently allowed to use.
+// Bands this package does not know are ignored.
+func (mm *ModemManager) CurrentBands() Bands {
+	bands, _ := ParseBands(mm.Modem.Generic.CurrentBands)
+	return bands
+}
+
+// SupportedBands returns the bands the modem supports. Bands this package
+// does not know are ignored.
+func (mm *ModemManager) SupportedBands() Bands {
+	bands, _ := ParseBands(mm.Modem.Generic.SupportedBands)
+	return bands
+}
@@ -0,0 +1,209 @@
+package mmcli
+
+// bandInfo holds the duplex mode and nominal frequencies of a band
+type bandInfo struct {
+	duplex   Duplex
+	uplink   FrequencyRange
+	downlink FrequencyRange
+}
+
+func fdd(ulLow, ulHigh, dlLow, dlHigh float64) bandInfo {
+	return bandInfo{DuplexFDD, FrequencyRange{ulLow, ulHigh}, FrequencyRange{dlLow, dlHigh}}
+}
+
+func tdd(low, high float64) bandInfo {
+	return bandInfo{DuplexTDD, FrequencyRange{low, high}, FrequencyRange{low, high}}
+}
+
+func sdl(low, high float64) bandInfo {
+	return bandInfo{duplex: DuplexSDL, downlink: FrequencyRange{low, high}}
+}
+
+func sul(low, high float64) bandInfo {
+	return bandInfo{duplex: DuplexSUL, uplink: FrequencyRange{low, high}}
+}
+
+// gsmBands is based on 3GPP TS 45.005
+var gsmBands = map[string]bandInfo{
+	"egsm": fdd(880, 915, 925, 960),
+	"dcs":  fdd(1710, 1785, 1805, 1880),
+	"pcs":  fdd(1850, 1910, 1930, 1990),
+	"g850": fdd(824, 849, 869, 894),
+	"g450": fdd(450.4, 457.6, 460.4, 467.6),
+	"g480": fdd(478.8, 486, 488.8, 496),
+	"g750": fdd(777, 793, 747, 763),
+	"g380": fdd(380.2, 389.8, 390.2, 399.8),
+	"g410": fdd(410.2, 417.8, 420.2, 427.8),
+	"g710": fdd(698, 716, 728, 746),
+	"g810": fdd(806, 821, 851, 866),
+}
+
+// utranBands is based on 3GPP TS 25.101
+var utranBands = map[int]bandInfo{
+	1:  fdd(1920, 1980, 2110, 2170),
+	2:  fdd(1850, 1910, 1930, 1990),
+	3:  fdd(1710, 1785, 1805, 1880),
+	4:  fdd(1710, 1755, 2110, 2155),
+	5:  fdd(824, 849, 869, 894),
+	6:  fdd(830, 840, 875, 885),
+	7:  fdd(2500, 2570, 2620, 2690),
+	8:  fdd(880, 915, 925, 960),
+	9:  fdd(1749.9, 1784.9, 1844.9, 1879.9),
+	10: fdd(1710, 1770, 2110, 2170),
+	11: fdd(1427.9, 1447.9, 1475.9, 1495.9),
+	12: fdd(699, 716, 729, 746),
+	13: fdd(777, 787, 746, 756),
+	14: fdd(788, 798, 758, 768),
+	19: fdd(830, 845, 875, 890),
+	20: fdd(832, 862, 791, 821),
+	21: fdd(1447.9, 1462.9, 1495.9, 1510.9),
+	22: fdd(3410, 3490, 3510, 3590),
+	25: fdd(1850, 1915, 1930, 1995),
+	26: fdd(814, 849, 859, 894),
+	32: sdl(1452, 1496),
+}
+
+// eutranBands is based on 3GPP TS 36.101
+var eutranBands = map[int]bandInfo{
+	1:  fdd(1920, 1980, 2110, 2170),
+	2:  fdd(1850, 1910, 1930, 1990),
+	3:  fdd(1710, 1785, 1805, 1880),
+	4:  fdd(1710, 1755, 2110, 2155),
+	5:  fdd(824, 849, 869, 894),
+	6:  fdd(830, 840, 875, 885),
+	7:  fdd(2500, 2570, 2620, 2690),
+	8:  fdd(880, 915, 925, 960),
+	9:  fdd(1749.9, 1784.9, 1844.9, 1879.9),
+	10: fdd(1710, 1770, 2110, 2170),
+	11: fdd(1427.9, 1447.9, 1475.9, 1495.9),
+	12: fdd(699, 716, 729, 746),
+	13: fdd(777, 787, 746, 756),
+	14: fdd(788, 798, 758, 768),
+	17: fdd(704, 716, 734, 746),
+	18: fdd(815, 830, 860, 875),
+	19: fdd(830, 845, 875, 890),
+	20: fdd(832, 862, 791, 821),
+	21: fdd(1447.9, 1462.9, 1495.9, 1510.9),
+	22: fdd(3410, 3490, 3510, 3590),
+	23: fdd(2000, 2020, 2180, 2200),
+	24: fdd(1626.5, 1660.5, 1525, 1559),
+	25: fdd(1850, 1915, 1930, 1995),
+	26: fdd(814, 849, 859, 894),
+	27: fdd(807, 824, 852, 869),
+	28: fdd(703, 748, 758, 803),
+	29: sdl(717, 728),
+	30: fdd(2305, 2315, 2350, 2360),
+	31: fdd(452.5, 457.5, 462.5, 467.5),
+	32: sdl(1452, 1496),
+	33: tdd(1900, 1920),
+	34: tdd(2010, 2025),
+	35: tdd(1850, 1910),
+	36: tdd(1930, 1990),
+	37: tdd(1910, 1930),
+	38: tdd(2570, 2620),
+	39: tdd(1880, 1920),
+	40: tdd(2300, 2400),
+	41: tdd(2496, 2690),
+	42: tdd(3400, 3600),
+	43: tdd(3600, 3800),
+	44: tdd(703, 803),
+	45: tdd(1447, 1467),
+	46: tdd(5150, 5925),
+	47: tdd(5855, 5925),
+	48: tdd(3550, 3700),
+	49: tdd(3550, 3700),
+	50: tdd(1432, 1517),
+	51: tdd(1427, 1432),
+	52: tdd(3300, 3400),
+	53: tdd(2483.5, 2495),
+	65: fdd(1920, 2010, 2110, 2200),
+	66: fdd(1710, 1780, 2110, 2200),
+	67: sdl(738, 758),
+	68: fdd(698, 728, 753, 783),
+	69: sdl(2570, 2620),
+	70: fdd(1695, 1710, 1995, 2020),
+	71: fdd(663, 698, 617, 652),
+	72: fdd(451, 456, 461, 466),
+	73: fdd(450, 455, 460, 465),
+	74: fdd(1427, 1470, 1475, 1518),
+	75: sdl(1432, 1517),
+	76: sdl(1427, 1432),
+	85: fdd(698, 716, 728, 746),
+}
+
+// ngranBands is based on 3GPP TS 38.101-1 (FR1) and TS 38.101-2 (FR2)
+var ngranBands = map[int]bandInfo{
+	1:   fdd(1920, 1980, 2110, 2170),
+	2:   fdd(1850, 1910, 1930, 1990),
+	3:   fdd(1710, 1785, 1805, 1880),
+	5:   fdd(824, 849, 869, 894),
+	7:   fdd(2500, 2570, 2620, 2690),
+	8:   fdd(880, 915, 925, 960),
+	12:  fdd(699, 716, 729, 746),
+	13:  fdd(777, 787, 746, 756),
+	14:  fdd(788, 798, 758, 768),
+	18:  fdd(815, 830, 860, 875),
+	20:  fdd(832, 862, 791, 821),
+	24:  fdd(1626.5, 1660.5, 1525, 1559),
+	25:  fdd(1850, 1915, 1930, 1995),
+	26:  fdd(814, 849, 859, 894),
+	28:  fdd(703, 748, 758, 803),
+	29:  sdl(717, 728),
+	30:  fdd(2305, 2315, 2350, 2360),
+	34:  tdd(2010, 2025),
+	38:  tdd(2570, 2620),
+	39:  tdd(1880, 1920),
+	40:  tdd(2300, 2400),
+	41:  tdd(2496, 2690),
+	46:  tdd(5150, 5925),
+	47:  tdd(5855, 5925),
+	48:  tdd(3550, 3700),
+	50:  tdd(1432, 1517),
+	51:  tdd(1427, 1432),
+	53:  tdd(2483.5, 2495),
+	65:  fdd(1920, 2010, 2110, 2200),
+	66:  fdd(1710, 1780, 2110, 2200),
+	67:  sdl(738, 758),
+	70:  fdd(1695, 1710, 1995, 2020),
+	71:  fdd(663, 698, 617, 652),
+	74:  fdd(1427, 1470, 1475, 1518),
+	75:  sdl(1432, 1517),
+	76:  sdl(1427, 1432),
+	77:  tdd(3300, 4200),
+	78:  tdd(3300, 3800),
+	79:  tdd(4400, 5000),
+	80:  sul(1710, 1785),
+	81:  sul(880, 915),
+	82:  sul(832, 862),
+	83:  sul(703, 748),
+	84:  sul(1920, 1980),
+	85:  fdd(698, 716, 728, 746),
+	86:  sul(1710, 1780),
+	89:  sul(824, 849),
+	90:  tdd(2496, 2690),
+	91:  fdd(832, 862, 1427, 1432),
+	92:  fdd(832, 862, 1432, 1517),
+	93:  fdd(880, 915, 1427, 1432),
+	94:  fdd(880, 915, 1432, 1517),
+	95:  sul(2010, 2025),
+	96:  tdd(5925, 7125),
+	97:  sul(2300, 2400),
+	98:  sul(1880, 1920),
+	99:  sul(1626.5, 1660.5),
+	100: fdd(874.4, 880, 919.4, 925),
+	101: tdd(1900, 1910),
+	102: tdd(5925, 6425),
+	104: tdd(6425, 7125),
+	257: tdd(26500, 29500),
+	258: tdd(24250, 27500),
+	259: tdd(39500, 43500),
+	260: tdd(37000, 40000),
+	261: tdd(27500, 28350),
+	262: tdd(47200, 48200),
+}
+
+// cdmaBands is based on 3GPP2 C.S0057, only the common band classes are listed
+var cdmaBands = map[int]bandInfo{
+	0: fdd(824, 849, 869, 894),
+	1: fdd(1850, 1910, 1930, 1990),
+}
@@ -0,0 +1,146 @@
+package mmcli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBand(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   Band
+		name   string
+		tech   BandTechnology
+		number int
+	}{
+		{"egsm", 1, "egsm", BandTechnologyGSM, 0},
+		{"utran-1", 5, "utran-1", BandTechnologyUTRAN, 1},
+		{"utran-2", 12, "utran-2", BandTechnologyUTRAN, 2},
+		{"utran-19", 219, "utran-19", BandTechnologyUTRAN, 19},
+		{"eutran-3", 33, "eutran-3", BandTechnologyEUTRAN, 3},
+		{"B20", 50, "eutran-20", BandTechnologyEUTRAN, 20},
+		{"eutran-85", 115, "eutran-85", BandTechnologyEUTRAN, 85},
+		{"cdma-bc0", 128, "cdma-bc0", BandTechnologyCDMA, 0},
+		{"ngran-78", 378, "ngran-78", BandTechnologyNGRAN, 78},
+		{"n258", 558, "ngran-258", BandTechnologyNGRAN, 258},
+		{"any", BandAny, "any", BandTechnologyUnknown, 0},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseBand(tt.input)
+		if err != nil {
+			t.Errorf("ParseBand(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBand(%q) = %d, expected %d", tt.input, got, tt.want)
+		}
+		if got.String() != tt.name {
+			t.Errorf("%q: String() = %s, expected %s", tt.input, got, tt.name)
+		}
+		if got.Technology() != tt.tech {
+			t.Errorf("%q: Technology() = %s, expected %s", tt.input, got.Technology(), tt.tech)
+		}
+		if got.Number() != tt.number {
+			t.Errorf("%q: Number() = %d, expected %d", tt.input, got.Number(), tt.number)
+		}
+	}
+
+	for _, input := range []string{"eutran-86", "utran-33", "ngran-0", "lte-3", "", "band"} {
+		if _, err := ParseBand(input); err == nil {
+			t.Errorf("ParseBand(%q): expected an error", input)
+		}
+	}
+
+	if EUTRANBand(3) != 33 || UTRANBand(8) != 10 || NGRANBand(78) != 378 {
+		t.Error("Unexpected band from constructor")
+	}
+}
+
+func TestBandFrequencies(t *testing.T) {
+	tests := []struct {
+		band     Band
+		duplex   Duplex
+		uplink   FrequencyRange
+		downlink FrequencyRange
+	}{
+		{EUTRANBand(3), DuplexFDD, FrequencyRange{1710, 1785}, FrequencyRange{1805, 1880}},
+		{EUTRANBand(20), DuplexFDD, FrequencyRange{832, 862}, FrequencyRange{791, 821}},
+		{EUTRANBand(38), DuplexTDD, FrequencyRange{2570, 2620}, FrequencyRange{2570, 2620}},
+		{EUTRANBand(32), DuplexSDL, FrequencyRange{}, FrequencyRange{1452, 1496}},
+		{UTRANBand(1), DuplexFDD, FrequencyRange{1920, 1980}, FrequencyRange{2110, 2170}},
+		{NGRANBand(78), DuplexTDD, FrequencyRange{3300, 3800}, FrequencyRange{3300, 3800}},
+		{NGRANBand(80), DuplexSUL, FrequencyRange{1710, 1785}, FrequencyRange{}},
+		{1, DuplexFDD, FrequencyRange{880, 915}, FrequencyRange{925, 960}},
+		{UTRANBand(15), DuplexUnknown, FrequencyRange{}, FrequencyRange{}},
+		{BandAny, DuplexUnknown, FrequencyRange{}, FrequencyRange{}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.band.Duplex(); got != tt.duplex {
+			t.Errorf("%s: Duplex() = %s, expected %s", tt.band, got, tt.duplex)
+		}
+		if got := tt.band.Uplink(); got != tt.uplink {
+			t.Errorf("%s: Uplink() = %s, expected %s", tt.band, got, tt.uplink)
+		}
+		if got := tt.band.Downlink(); got != tt.downlink {
+			t.Errorf("%s: Downlink() = %s, expected %s", tt.band, got, tt.downlink)
+		}
+	}
+
+	dl := EUTRANBand(3).Downlink()
+	if dl.String() != "1805-1880 MHz" {
+		t.Errorf("Expected 1805-1880 MHz, got %s", dl)
+	}
+	if !dl.Contains(1842.5) || dl.Contains(1710) {
+		t.Error("Unexpected Contains result")
+	}
+}
+
+func TestBandSets(t *testing.T) {
+	modem, err := ParseBands([]string{"egsm", "eutran-1", "eutran-3", "eutran-20", "eutran-3"})
+	if err != nil {
+		t.Fatalf("Failed to parse bands: %v", err)
+	}
+	carrier, err := ParseBands([]string{"B3", "B7", "B20", "n78"})
+	if err != nil {
+		t.Fatalf("Failed to parse bands: %v", err)
+	}
+
+	if got := modem.Intersect(carrier).Strings(); !reflect.DeepEqual(got, []string{"eutran-3", "eutran-20"}) {
+		t.Errorf("Unexpected intersection %v", got)
+	}
+	if got := carrier.Difference(modem).Strings(); !reflect.DeepEqual(got, []string{"eutran-7", "ngran-78"}) {
+		t.Errorf("Unexpected difference %v", got)
+	}
+	if got := modem.Union(carrier); len(got) != 6 {
+		t.Errorf("Expected 6 bands in the union, got %v", got.Strings())
+	}
+	if got := modem.Technology(BandTechnologyEUTRAN); len(got) != 3 {
+		t.Errorf("Expected 3 LTE bands, got %v", got.Strings())
+	}
+	if !modem.Contains(EUTRANBand(20)) || modem.Contains(EUTRANBand(7)) {
+		t.Error("Unexpected Contains result")
+	}
+
+	bands, err := ParseBands([]string{"eutran-3", "eutran-99"})
+	if err == nil {
+		t.Error("Expected an error for an unknown band")
+	}
+	if len(bands) != 1 {
+		t.Errorf("Expected the known bands to be kept, got %v", bands.Strings())
+	}
+}
+
+func TestModemManagerBands(t *testing.T) {
+	var mm ModemManager
+	mm.Modem.Generic.CurrentBands = []string{"eutran-3", "eutran-20"}
+	mm.Modem.Generic.SupportedBands = []string{"egsm", "utran-1", "eutran-3", "eutran-20", "ngran-78"}
+
+	if got := mm.CurrentBands(); len(got) != 2 || got[1] != EUTRANBand(20) {
+		t.Errorf("Unexpected current bands %v", got.Strings())
+	}
+	if got := mm.SupportedBands().Difference(mm.CurrentBands()); len(got) != 3 {
+		t.Errorf("Expected 3 unused bands, got %v", got.Strings())
+	}
+}
@@ -0,0 +1,160 @@
+package mmcli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client runs mmcli operations through a Runner. The zero value is ready to
+// use and runs the mmcli binary from PATH.
+type Client struct {
+	runner Runner
+	format Format
+	logger *slog.Logger
+
+	versionMu     sync.Mutex
+	version       *Version
+	daemonVersion *Version
+	daemonChecked bool // daemon version lookup done, even if it failed
+	versionFixed  bool // version set with WithVersion
+	daemonFixed   bool // daemon version set with WithDaemonVersion
+
+	stateMu       sync.Mutex
+	stateMonitors map[string]*stateMonitor
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithRunner replaces the runner passed to NewClient. A nil runner selects ExecRunner.
+func WithRunner(runner Runner) Option {
+	return func(c *Client) {
+		c.runner = runner
+	}
+}
+
+// WithFormat sets the output format requested from mmcli. FormatJSON is the default.
+func WithFormat(format Format) Option {
+	return func(c *Client) {
+		c.format = format
+	}
+}
+
+// WithVersion sets the mmcli version used for feature checks instead of
+// detecting it with mmcli -V
+func WithVersion(version Version) Option {
+	return func(c *Client) {
+		c.version = &version
+		c.versionFixed = true
+	}
+}
+
+// WithDaemonVersion sets the ModemManager daemon version used for feature
+// checks instead of detecting it with mmcli -B
+func WithDaemonVersion(version Version) Option {
+	return func(c *Client) {
+		c.daemonVersion = &version
+		c.daemonChecked, c.daemonFixed = true, true
+	}
+}
+
+// NewClient returns a Client using the given runner, configured with the
+// given options. A nil runner selects ExecRunner.
+func NewClient(runner Runner, opts ...Option) *Client {
+	c := &Client{runner: runner}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// DefaultClient is the Client used by the package-level functions
+var DefaultClient = NewClient(nil)
+
+// Runner returns the runner used by the client
+func (c *Client) Runner() Runner {
+	if c == nil || c.runner == nil {
+		return ExecRunner{}
+	}
+	return c.runner
+}
+
+// Format returns the output format requested from mmcli
+func (c *Client) Format() Format {
+	if c == nil {
+		return FormatJSON
+	}
+	return c.format
+}
+
+// output returns the mmcli option selecting the client's output format
+func (c *Client) output() string {
+	return c.Format().flag()
+}
+
+// unmarshal decodes mmcli output in the client's format into v
+func (c *Client) unmarshal(out []byte, v interface{}) error {
+	if c.Format() == FormatKeyValue {
+		return unmarshalKeyValue(out, v)
+	}
+	return json.Unmarshal(out, v)
+}
+
+// run invokes mmcli and returns its standard output
+func (c *Client) run(ctx context.Context, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkSupported(ctx, args); err != nil {
+		return nil, err
+	}
+
+	args = withTimeout(ctx, args)
+	start := time.Now()
+	res, err := c.Runner().Run(ctx, args...)
+	c.logInvocation(ctx, args, time.Since(start), res, err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if res.ExitCode != 0 {
+		return nil, parseError(args, res)
+	}
+
+	return res.Stdout, nil
+}
+
+// withTimeout appends mmcli's --timeout option derived from the context
+// deadline, so that mmcli gives up on its D-Bus call before it is killed.
+// Arguments that already carry a timeout are left untouched.
+func withTimeout(ctx context.Context, args []string) []string {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return args
+	}
+
+	for _, arg := range args {
+		if arg == "--timeout" || strings.HasPrefix(arg, "--timeout=") {
+			return args
+		}
+	}
+
+	seconds := int(time.Until(deadline) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	out := make([]string, len(args), len(args)+1)
+	copy(out, args)
+	return append(out, fmt.Sprintf("--timeout=%d", seconds))
+}
@@ -1,8 +1,8 @@
 package mmcli
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 	"strings"
 )
 
@@ -19,6 +19,16 @@ type ConnectSettings struct {
 
 // Connect establishes a connection with the specified settings
 func Connect(modemID string, settings ConnectSettings) error {
+	return ConnectContext(context.Background(), modemID, settings)
+}
+
+// ConnectContext is like Connect but uses ctx to bound the mmcli invocation
+func ConnectContext(ctx context.Context, modemID string, settings ConnectSettings) error {
+	return DefaultClient.Connect(ctx, modemID, settings)
+}
+
+// Connect establishes a connection with the specified settings
+func (c *Client) Connect(ctx context.Context, modemID string, settings ConnectSettings) error {
 	// Build the settings string
 	var settingsParams []string
 	if settings.APN != "" {
@@ -55,7 +65,7 @@ func Connect(modemID string, settings ConnectSettings) error {
 	}
 
 	// Execute the command
-	_, err := exec.Command("mmcli", args...).Output()
+	_, err := c.run(ctx, args...)
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
@@ -65,7 +75,17 @@ func Connect(modemID string, settings ConnectSettings) error {
 
 // Disconnect disconnects all connected bearers
 func Disconnect(modemID string) error {
-	_, err := exec.Command("mmcli", "-m", modemID, "--simple-disconnect").Output()
+	return DisconnectContext(context.Background(), modemID)
+}
+
+// DisconnectContext is like Disconnect but uses ctx to bound the mmcli invocation
+func DisconnectContext(ctx context.Context, modemID string) error {
+	return DefaultClient.Disconnect(ctx, modemID)
+}
+
+// Disconnect disconnects all connected bearers
+func (c *Client) Disconnect(ctx context.Context, modemID string) error {
+	_, err := c.run(ctx, "-m", modemID, "--simple-disconnect")
 	if err != nil {
 		return fmt.Errorf("failed to disconnect: %w", err)
 	}
@@ -75,18 +95,79 @@ func Disconnect(modemID string) error {
 
 // ConnectWithAPN is a convenience function to connect with just an APN
 func ConnectWithAPN(modemID string, apn string) error {
+	return ConnectWithAPNContext(context.Background(), modemID, apn)
+}
+
+// ConnectWithAPNContext is like ConnectWithAPN but uses ctx to bound the mmcli invocation
+func ConnectWithAPNContext(ctx context.Context, modemID string, apn string) error {
+	return DefaultClient.ConnectWithAPN(ctx, modemID, apn)
+}
+
+// ConnectWithAPN is a convenience function to connect with just an APN
+func (c *Client) ConnectWithAPN(ctx context.Context, modemID string, apn string) error {
 	settings := ConnectSettings{
 		APN: apn,
 	}
-	return Connect(modemID, settings)
+	return c.Connect(ctx, modemID, settings)
 }
 
 // ConnectWithAuth is a convenience function to connect with APN, username and password
 func ConnectWithAuth(modemID string, apn, user, password string) error {
+	return ConnectWithAuthContext(context.Background(), modemID, apn, user, password)
+}
+
+// ConnectWithAuthContext is like ConnectWithAuth but uses ctx to bound the mmcli invocation
+func ConnectWithAuthContext(ctx context.Context, modemID string, apn, user, password string) error {
+	return DefaultClient.ConnectWithAuth(ctx, modemID, apn, user, password)
+}
+
+// ConnectWithAuth is a convenience function to connect with APN, username and password
+func (c *Client) ConnectWithAuth(ctx context.Context, modemID string, apn, user, password string) error {
 	settings := ConnectSettings{
 		APN:      apn,
 		User:     user,
 		Password: password,
 	}
-	return Connect(modemID, settings)
+	return c.Connect(ctx, modemID, settings)
+}
+
+// ListProfiles returns the connection profiles stored in the modem, one
+// string per profile as printed by mmcli. Requires ModemManager 1.18 or newer.
+func ListProfiles(modemID string) ([]string, error) {
+	return ListProfilesContext(context.Background(), modemID)
+}
+
+// ListProfilesContext is like ListProfiles but uses ctx to bound the mmcli invocation
+func ListProfilesContext(ctx context.Context, modemID string) ([]string, error) {
+	return DefaultClient.ListProfiles(ctx, modemID)
+}
+
+// ListProfiles returns the connection profiles stored in the modem
+func (c *Client) ListProfiles(ctx context.Context, modemID string) ([]string, error) {
+	out, err := c.run(ctx, "-m", modemID, "--3gpp-profile-manager-list", c.output())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	// mmcli prints lists under a dotted key, like the SMS list; accept the
+	// nested form as well
+	var response struct {
+		List  []string `json:"modem.3gpp.profile-manager.list"`
+		Modem struct {
+			ThreeGPP struct {
+				ProfileManager struct {
+					List []string `json:"list"`
+				} `json:"profile-manager"`
+			} `json:"3gpp"`
+		} `json:"modem"`
+	}
+
+	if err := c.unmarshal(out, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse profile list: %w", err)
+	}
+
+	if response.List != nil {
+		return response.List, nil
+	}
+	return response.Modem.ThreeGPP.ProfileManager.List, nil
 }
@@ -0,0 +1,248 @@
+package mmcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSignalThreshold is the signal quality change in percent points
+// that Diff reports
+const DefaultSignalThreshold = 5
+
+// Change is a meaningful change between two modem snapshots. It is one of
+// StateChange, OperatorChange, AccessTechnologyChange, SignalChange,
+// BandsChange, SIMChange or BearersChange.
+type Change interface {
+	fmt.Stringer
+	change()
+}
+
+// StateChange reports a modem state transition. Reason is set if the modem
+// entered the failed state.
+type StateChange struct {
+	Old, New ModemState
+	Reason   StateFailedReason
+}
+
+func (c StateChange) String() string {
+	if c.New == ModemStateFailed {
+		return fmt.Sprintf("state: %s -> %s (%s)", c.Old, c.New, c.Reason)
+	}
+	return fmt.Sprintf("state: %s -> %s", c.Old, c.New)
+}
+
+// OperatorChange reports that the modem registered with another operator
+type OperatorChange struct {
+	OldName, OldCode string
+	NewName, NewCode string
+}
+
+func (c OperatorChange) String() string {
+	return fmt.Sprintf("operator: %s -> %s", operatorString(c.OldName, c.OldCode), operatorString(c.NewName, c.NewCode))
+}
+
+func operatorString(name, code string) string {
+	switch {
+	case !IsSet(name) && !IsSet(code):
+		return Unset
+	case !IsSet(code):
+		return name
+	case !IsSet(name):
+		return code
+	}
+	return fmt.Sprintf("%s (%s)", name, code)
+}
+
+// AccessTechnologyChange reports a change of the access technologies in use
+type AccessTechnologyChange struct {
+	Old, New AccessTechnology
+}
+
+func (c AccessTechnologyChange) String() string {
+	return fmt.Sprintf("access technology: %s -> %s", c.Old, c.New)
+}
+
+// SignalChange reports a change of the signal quality. Old or New is nil if
+// the signal quality was or became unknown.
+type SignalChange struct {
+	Old, New *int
+}
+
+// Delta returns the change in percent points, or 0 if either value is unknown
+func (c SignalChange) Delta() int {
+	if c.Old == nil || c.New == nil {
+		return 0
+	}
+	return *c.New - *c.Old
+}
+
+func (c SignalChange) String() string {
+	return fmt.Sprintf("signal: %s -> %s", percentString(c.Old), percentString(c.New))
+}
+
+func percentString(p *int) string {
+	if p == nil {
+		return Unset
+	}
+	return fmt.Sprintf("%d%%", *p)
+}
+
+// BandsChange reports a change of the bands the modem is allowed to use
+type BandsChange struct {
+	Added, Removed Bands
+}
+
+func (c BandsChange) String() string {
+	return "bands: " + addedRemovedString(c.Added.Strings(), c.Removed.Strings())
+}
+
+// SIMChange reports that a SIM was inserted, removed or replaced. Old and
+// New are D-Bus paths, empty if there was no SIM.
+type SIMChange struct {
+	Old, New string
+}
+
+func (c SIMChange) String() string {
+	return fmt.Sprintf("sim: %s -> %s", pathString(c.Old), pathString(c.New))
+}
+
+func pathString(path string) string {
+	if !IsSet(path) {
+		return Unset
+	}
+	return path
+}
+
+// BearersChange reports that bearers were created or removed. The bearers
+// are identified by their D-Bus path.
+type BearersChange struct {
+	Added, Removed []string
+}
+
+func (c BearersChange) String() string {
+	return "bearers: " + addedRemovedString(c.Added, c.Removed)
+}
+
+func addedRemovedString(added, removed []string) string {
+	var parts []string
+	for _, s := range added {
+		parts = append(parts, "+"+s)
+	}
+	for _, s := range removed {
+		parts = append(parts, "-"+s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (StateChange) change()            {}
+func (OperatorChange) change()         {}
+func (AccessTechnologyChange) change() {}
+func (SignalChange) change()           {}
+func (BandsChange) change()            {}
+func (SIMChange) change()              {}
+func (BearersChange) change()          {}
+
+// Differ compares modem snapshots
+type Differ struct {
+	// SignalThreshold is the minimum signal quality change in percent points
+	// reported as a SignalChange. Zero reports every change.
+	SignalThreshold int
+}
+
+// Diff returns the changes between two snapshots of the same modem, using
+// DefaultSignalThreshold. A nil snapshot is treated as an empty one.
+func Diff(old, new *ModemManager) []Change {
+	return Differ{SignalThreshold: DefaultSignalThreshold}.Diff(old, new)
+}
+
+// Diff returns the changes between two snapshots of the same modem, in the
+// order of the Change types. A nil snapshot is treated as an empty one.
+//
+// Signal changes below the threshold are not reported, so to catch slow
+// drifts keep comparing against the snapshot you last acted on rather than
+// the previous one.
+func (d Differ) Diff(old, new *ModemManager) []Change {
+	if old == nil {
+		old = &ModemManager{}
+	}
+	if new == nil {
+		new = &ModemManager{}
+	}
+	var changes []Change
+
+	if o, n := old.State(), new.State(); o != n {
+		changes = append(changes, StateChange{Old: o, New: n, Reason: new.FailedReason()})
+	}
+
+	o3, n3 := old.Modem.ThreeGPP, new.Modem.ThreeGPP
+	if optional(o3.OperatorName) != optional(n3.OperatorName) || optional(o3.OperatorCode) != optional(n3.OperatorCode) {
+		changes = append(changes, OperatorChange{
+			OldName: o3.OperatorName, OldCode: o3.OperatorCode,
+			NewName: n3.OperatorName, NewCode: n3.OperatorCode,
+		})
+	}
+
+	if o, n := old.AccessTechnologies(), new.AccessTechnologies(); o != n {
+		changes = append(changes, AccessTechnologyChange{Old: o, New: n})
+	}
+
+	signal := SignalChange{Old: old.Modem.Generic.SignalQuality.Percent, New: new.Modem.Generic.SignalQuality.Percent}
+	if d.signalChanged(signal) {
+		changes = append(changes, signal)
+	}
+
+	if o, n := old.CurrentBands(), new.CurrentBands(); len(o.Difference(n)) > 0 || len(n.Difference(o)) > 0 {
+		changes = append(changes, BandsChange{Added: n.Difference(o), Removed: o.Difference(n)})
+	}
+
+	if o, n := optional(old.Modem.Generic.SIM), optional(new.Modem.Generic.SIM); o != n {
+		changes = append(changes, SIMChange{Old: o, New: n})
+	}
+
+	o, n := old.Modem.Generic.Bearers, new.Modem.Generic.Bearers
+	if added, removed := stringsDifference(n, o), stringsDifference(o, n); len(added) > 0 || len(removed) > 0 {
+		changes = append(changes, BearersChange{Added: added, Removed: removed})
+	}
+
+	return changes
+}
+
+func (d Differ) signalChanged(c SignalChange) bool {
+	if (c.Old == nil) != (c.New == nil) {
+		return true
+	}
+	if c.Old == nil {
+		return false
+	}
+	delta := c.Delta()
+	if delta < 0 {
+		delta = -delta
+	}
+	return delta > 0 && delta >= d.SignalThreshold
+}
+
+// optional returns s, or an empty string if mmcli printed "--"
+func optional(s string) string {
+	if !IsSet(s) {
+		return ""
+	}
+	return s
+}
+
+// stringsDifference returns the strings in a that are not in b
+func stringsDifference(a, b []string) []string {
+	var out []string
+	for _, s := range a {
+		found := false
+		for _, t := range b {
+			if s == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, s)
+		}
+	}
+	return out
+}
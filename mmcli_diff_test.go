@@ -0,0 +1,115 @@
+package mmcli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func snapshot(state, operator, tech, signal, sim string, bands, bearers []string) *ModemManager {
+	var mm ModemManager
+	mm.Modem.Generic.State = state
+	mm.Modem.ThreeGPP.OperatorName = operator
+	mm.Modem.ThreeGPP.OperatorCode = "26201"
+	mm.Modem.Generic.AccessTechnologies = []string{tech}
+	mm.Modem.Generic.SignalQuality = SignalQuality{Value: signal, Recent: "yes"}
+	mm.Modem.Generic.SIM = sim
+	mm.Modem.Generic.CurrentBands = bands
+	mm.Modem.Generic.Bearers = bearers
+	mm.Normalize()
+	return &mm
+}
+
+func percent(n int) *int {
+	return &n
+}
+
+func TestDiff(t *testing.T) {
+	const sim = "/org/freedesktop/ModemManager1/SIM/0"
+	const bearer = "/org/freedesktop/ModemManager1/Bearer/0"
+	old := snapshot("registered", "Telekom.de", "umts", "60", sim, []string{"eutran-3", "eutran-20"}, nil)
+
+	if changes := Diff(old, old); changes != nil {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+
+	new := snapshot("connected", "Telekom.de", "lte", "50", "--", []string{"eutran-3", "eutran-7"}, []string{bearer})
+	expected := []Change{
+		StateChange{Old: ModemStateRegistered, New: ModemStateConnected},
+		AccessTechnologyChange{Old: AccessTechnologyUMTS, New: AccessTechnologyLTE},
+		SignalChange{Old: percent(60), New: percent(50)},
+		BandsChange{Added: Bands{EUTRANBand(7)}, Removed: Bands{EUTRANBand(20)}},
+		SIMChange{Old: sim},
+		BearersChange{Added: []string{bearer}},
+	}
+	changes := Diff(old, new)
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Expected %v, got %v", expected, changes)
+	}
+
+	descriptions := []string{
+		"state: registered -> connected",
+		"access technology: umts -> lte",
+		"signal: 60% -> 50%",
+		"bands: +eutran-7 -eutran-20",
+		"sim: " + sim + " -> --",
+		"bearers: +" + bearer,
+	}
+	for i, change := range changes {
+		if change.String() != descriptions[i] {
+			t.Errorf("Expected %q, got %q", descriptions[i], change)
+		}
+	}
+}
+
+func TestDiffOperatorAndFailure(t *testing.T) {
+	old := snapshot("registered", "Telekom.de", "lte", "60", "", nil, nil)
+	new := snapshot("failed", "--", "lte", "60", "", nil, nil)
+	new.Modem.ThreeGPP.OperatorCode = "--"
+	new.Modem.Generic.StateFailedReason = "sim-missing"
+
+	changes := Diff(old, new)
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %v", changes)
+	}
+	if s := changes[0].String(); s != "state: registered -> failed (sim-missing)" {
+		t.Errorf("Unexpected state change %q", s)
+	}
+	if s := changes[1].String(); s != "operator: Telekom.de (26201) -> --" {
+		t.Errorf("Unexpected operator change %q", s)
+	}
+}
+
+func TestDiffSignalThreshold(t *testing.T) {
+	old := snapshot("connected", "Telekom.de", "lte", "60", "", nil, nil)
+
+	tests := []struct {
+		signal    string
+		threshold int
+		changed   bool
+	}{
+		{"63", DefaultSignalThreshold, false},
+		{"55", DefaultSignalThreshold, true},
+		{"61", 0, true},
+		{"60", 0, false},
+		{"--", 50, true},
+	}
+
+	for _, tt := range tests {
+		new := snapshot("connected", "Telekom.de", "lte", tt.signal, "", nil, nil)
+		changes := Differ{SignalThreshold: tt.threshold}.Diff(old, new)
+		if changed := len(changes) > 0; changed != tt.changed {
+			t.Errorf("Signal 60 -> %s with threshold %d: expected changed=%v, got %v", tt.signal, tt.threshold, tt.changed, changes)
+		}
+	}
+}
+
+func TestDiffNil(t *testing.T) {
+	mm := snapshot("connected", "Telekom.de", "lte", "60", "", nil, nil)
+	changes := Diff(nil, mm)
+	if len(changes) == 0 {
+		t.Fatal("Expected changes from an empty snapshot")
+	}
+	if c, ok := changes[0].(StateChange); !ok || c.Old != ModemStateUnknown || c.New != ModemStateConnected {
+		t.Errorf("Expected a state change from unknown, got %v", changes[0])
+	}
+}
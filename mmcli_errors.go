@@ -0,0 +1,132 @@
+package mmcli
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Sentinel errors for common failure reasons, usable with errors.Is
+var (
+	ErrModemNotFound  = errors.New("modem not found")
+	ErrNotFound       = errors.New("object not found")
+	ErrWrongState     = errors.New("modem in wrong state")
+	ErrSimPinRequired = errors.New("SIM PIN required")
+	ErrSimPukRequired = errors.New("SIM PUK required")
+	ErrSimNotInserted = errors.New("SIM not inserted")
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrTimeout        = errors.New("operation timed out")
+	ErrUnsupported    = errors.New("operation not supported")
+)
+
+// dbusErrorSentinels maps D-Bus error names to sentinel errors
+var dbusErrorSentinels = map[string]error{
+	"org.freedesktop.ModemManager1.Error.Core.WrongState":                ErrWrongState,
+	"org.freedesktop.ModemManager1.Error.Core.Unsupported":               ErrUnsupported,
+	"org.freedesktop.ModemManager1.Error.Core.Timeout":                   ErrTimeout,
+	"org.freedesktop.ModemManager1.Error.Core.Unauthorized":              ErrUnauthorized,
+	"org.freedesktop.ModemManager1.Error.MobileEquipment.SimPin":         ErrSimPinRequired,
+	"org.freedesktop.ModemManager1.Error.MobileEquipment.SimPuk":         ErrSimPukRequired,
+	"org.freedesktop.ModemManager1.Error.MobileEquipment.SimNotInserted": ErrSimNotInserted,
+	"org.freedesktop.ModemManager1.Error.MobileEquipment.NotSupported":   ErrUnsupported,
+	"org.freedesktop.DBus.Error.Timeout":                                 ErrTimeout,
+	"org.freedesktop.DBus.Error.NoReply":                                 ErrTimeout,
+	"org.freedesktop.DBus.Error.AccessDenied":                            ErrUnauthorized,
+	"org.freedesktop.DBus.Error.UnknownMethod":                           ErrUnsupported,
+}
+
+// gdbusErrorRe matches the remote D-Bus error embedded in mmcli error messages,
+// e.g. "GDBus.Error:org.freedesktop.ModemManager1.Error.Core.WrongState: modem not enabled"
+var gdbusErrorRe = regexp.MustCompile(`GDBus\.Error:([A-Za-z0-9_.]+):\s*(.*)$`)
+
+// Error describes a failed mmcli invocation
+type Error struct {
+	Args     []string // Arguments mmcli was invoked with, with secrets redacted
+	ExitCode int      // Exit status of mmcli
+	Stderr   string   // Raw standard error output
+
+	Name    string // D-Bus error name, e.g. org.freedesktop.ModemManager1.Error.Core.WrongState
+	Domain  string // D-Bus error domain, e.g. org.freedesktop.ModemManager1.Error.Core
+	Message string // D-Bus error message, or mmcli's own message if there is no D-Bus error
+
+	sentinel error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("mmcli: %s (%s)", e.Message, e.Name)
+	}
+	if e.Message != "" {
+		return "mmcli: " + e.Message
+	}
+	return fmt.Sprintf("mmcli exited with status %d", e.ExitCode)
+}
+
+// Is reports whether the error matches one of the sentinel errors
+func (e *Error) Is(target error) bool {
+	if e.sentinel == nil {
+		return false
+	}
+	return target == e.sentinel || (e.sentinel == ErrModemNotFound && target == ErrNotFound)
+}
+
+// NewDBusError returns an Error for a D-Bus error reply received from
+// ModemManager, for backends that do not go through mmcli
+func NewDBusError(name, message string) *Error {
+	e := &Error{
+		Name:     name,
+		Message:  message,
+		sentinel: dbusErrorSentinels[name],
+	}
+	if i := strings.LastIndex(name, "."); i > 0 {
+		e.Domain = name[:i]
+	}
+	return e
+}
+
+// parseError builds an Error from a failed mmcli invocation
+func parseError(args []string, res *Result) *Error {
+	e := &Error{
+		Args:     RedactArgs(args),
+		ExitCode: res.ExitCode,
+		Stderr:   string(res.Stderr),
+	}
+
+	// mmcli reports failures as "error: <what failed>: '<reason>'"; keep the last such line
+	var line string
+	for _, l := range strings.Split(e.Stderr, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			line = l
+		}
+	}
+	line = strings.TrimPrefix(line, "error: ")
+	e.Message = line
+
+	if m := gdbusErrorRe.FindStringSubmatch(line); m != nil {
+		dbusErr := NewDBusError(m[1], strings.TrimSuffix(m[2], "'"))
+		e.Name, e.Domain, e.Message, e.sentinel = dbusErr.Name, dbusErr.Domain, dbusErr.Message, dbusErr.sentinel
+		return e
+	}
+
+	lower := strings.ToLower(line)
+	switch {
+	case strings.Contains(lower, "sim pin required"):
+		e.sentinel = ErrSimPinRequired
+	case strings.Contains(lower, "sim puk required"):
+		e.sentinel = ErrSimPukRequired
+	case strings.Contains(lower, "unauthorized"), strings.Contains(lower, "not authorized"):
+		e.sentinel = ErrUnauthorized
+	case strings.Contains(lower, "couldn't find modem"), strings.Contains(lower, "no modems were found"):
+		e.sentinel = ErrModemNotFound
+	case strings.Contains(lower, "couldn't find"):
+		e.sentinel = ErrNotFound
+	case strings.Contains(lower, "timeout was reached"):
+		e.sentinel = ErrTimeout
+	case strings.Contains(lower, "has no") && strings.Contains(lower, "capabilities"):
+		e.sentinel = ErrUnsupported
+	}
+
+	return e
+}
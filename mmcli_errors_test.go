@@ -0,0 +1,128 @@
+package mmcli
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		stderr   string
+		sentinel error
+		name     string
+		domain   string
+		message  string
+	}{
+		{
+			stderr:   "error: couldn't find modem\n",
+			sentinel: ErrModemNotFound,
+			message:  "couldn't find modem",
+		},
+		{
+			stderr:   "error: couldn't find SIM\n",
+			sentinel: ErrNotFound,
+			message:  "couldn't find SIM",
+		},
+		{
+			stderr:   "error: couldn't enable the modem: 'GDBus.Error:org.freedesktop.ModemManager1.Error.Core.WrongState: modem is locked'\n",
+			sentinel: ErrWrongState,
+			name:     "org.freedesktop.ModemManager1.Error.Core.WrongState",
+			domain:   "org.freedesktop.ModemManager1.Error.Core",
+			message:  "modem is locked",
+		},
+		{
+			stderr:   "error: couldn't connect the modem: 'GDBus.Error:org.freedesktop.ModemManager1.Error.MobileEquipment.SimPin: SIM PIN required'\n",
+			sentinel: ErrSimPinRequired,
+			name:     "org.freedesktop.ModemManager1.Error.MobileEquipment.SimPin",
+			domain:   "org.freedesktop.ModemManager1.Error.MobileEquipment",
+			message:  "SIM PIN required",
+		},
+		{
+			stderr:   "error: couldn't reset the modem: 'GDBus.Error:org.freedesktop.ModemManager1.Error.Core.Unauthorized: PolicyKit authorization failed'\n",
+			sentinel: ErrUnauthorized,
+			name:     "org.freedesktop.ModemManager1.Error.Core.Unauthorized",
+			domain:   "org.freedesktop.ModemManager1.Error.Core",
+			message:  "PolicyKit authorization failed",
+		},
+		{
+			stderr:   "error: couldn't scan networks in the modem: 'Timeout was reached'\n",
+			sentinel: ErrTimeout,
+			message:  "couldn't scan networks in the modem: 'Timeout was reached'",
+		},
+		{
+			stderr:   "error: modem has no location capabilities\n",
+			sentinel: ErrUnsupported,
+			message:  "modem has no location capabilities",
+		},
+		{
+			stderr:   "error: couldn't connect the modem: 'SIM PIN required'\n",
+			sentinel: ErrSimPinRequired,
+			message:  "couldn't connect the modem: 'SIM PIN required'",
+		},
+		{
+			stderr:   "error: couldn't unlock the modem: 'SIM PUK required'\n",
+			sentinel: ErrSimPukRequired,
+			message:  "couldn't unlock the modem: 'SIM PUK required'",
+		},
+		{
+			stderr:   "error: couldn't reset the modem: 'Unauthorized'\n",
+			sentinel: ErrUnauthorized,
+			message:  "couldn't reset the modem: 'Unauthorized'",
+		},
+		{
+			stderr:   "error: couldn't set logging level: 'Not authorized to perform operation'\n",
+			sentinel: ErrUnauthorized,
+			message:  "couldn't set logging level: 'Not authorized to perform operation'",
+		},
+		{
+			stderr:  "error: something unexpected\n",
+			message: "something unexpected",
+		},
+	}
+
+	sentinels := []error{ErrModemNotFound, ErrNotFound, ErrWrongState, ErrSimPinRequired,
+		ErrSimPukRequired, ErrSimNotInserted, ErrUnauthorized, ErrTimeout, ErrUnsupported}
+
+	for _, tt := range tests {
+		e := parseError([]string{"-m", "0"}, &Result{Stderr: []byte(tt.stderr), ExitCode: 1})
+		if e.Name != tt.name {
+			t.Errorf("%q: expected name %q, got %q", tt.stderr, tt.name, e.Name)
+		}
+		if e.Domain != tt.domain {
+			t.Errorf("%q: expected domain %q, got %q", tt.stderr, tt.domain, e.Domain)
+		}
+		if e.Message != tt.message {
+			t.Errorf("%q: expected message %q, got %q", tt.stderr, tt.message, e.Message)
+		}
+		for _, s := range sentinels {
+			want := s == tt.sentinel || (s == ErrNotFound && tt.sentinel == ErrModemNotFound)
+			if got := errors.Is(e, s); got != want {
+				t.Errorf("%q: errors.Is(%v) = %v, expected %v", tt.stderr, s, got, want)
+			}
+		}
+	}
+}
+
+func TestClientTypedError(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-m 3 -J": {Stderr: []byte("error: couldn't find modem\n"), ExitCode: 1},
+	}}
+	client := NewClient(runner)
+
+	_, err := client.GetModemDetails(context.Background(), "3")
+	if !errors.Is(err, ErrModemNotFound) {
+		t.Fatalf("Expected ErrModemNotFound, got %v", err)
+	}
+
+	var mmErr *Error
+	if !errors.As(err, &mmErr) {
+		t.Fatalf("Expected *Error, got %T", err)
+	}
+	if mmErr.ExitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", mmErr.ExitCode)
+	}
+	if len(mmErr.Args) != 3 || mmErr.Args[1] != "3" {
+		t.Errorf("Expected invocation arguments, got %v", mmErr.Args)
+	}
+}
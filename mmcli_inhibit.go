@@ -0,0 +1,166 @@
+package mmcli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInhibitionEnded is reported when mmcli exits while holding an
+// inhibition, which ModemManager then drops
+var ErrInhibitionEnded = errors.New("inhibition ended early")
+
+// Inhibit stops ModemManager from using a modem until the inhibition is
+// released or ctx is cancelled. See Client.Inhibit.
+func Inhibit(ctx context.Context, modemID string) (*Inhibition, error) {
+	return DefaultClient.Inhibit(ctx, modemID)
+}
+
+// InhibitDevice stops ModemManager from using the device with the given UID
+// until the inhibition is released or ctx is cancelled. See
+// Client.InhibitDevice.
+func InhibitDevice(ctx context.Context, uid string) (*Inhibition, error) {
+	return DefaultClient.InhibitDevice(ctx, uid)
+}
+
+// Inhibit stops ModemManager from using a modem, so that other tools such as
+// qmicli or a firmware updater can use its ports. The modem disappears from
+// ModemManager until the inhibition is released.
+//
+// mmcli keeps the inhibition only while it runs, so Inhibit starts a
+// long-lived mmcli --inhibit and returns once ModemManager confirmed the
+// inhibition. Release stops mmcli, which releases the inhibition; cancelling
+// ctx does the same. If mmcli exits on its own, the inhibition is lost: Done
+// is closed and Err and Release return ErrInhibitionEnded. The client's
+// runner must implement StreamRunner.
+func (c *Client) Inhibit(ctx context.Context, modemID string) (*Inhibition, error) {
+	inhibition, err := c.inhibit(ctx, "-m", modemID, "--inhibit")
+	if err != nil {
+		return nil, fmt.Errorf("failed to inhibit modem: %w", err)
+	}
+	return inhibition, nil
+}
+
+// InhibitDevice stops ModemManager from using the device with the given UID,
+// the physical device path mmcli reports as the modem's device. Unlike Inhibit, this also
+// works for a device that is not currently exposed as a modem. See Inhibit
+// for how the inhibition is released.
+func (c *Client) InhibitDevice(ctx context.Context, uid string) (*Inhibition, error) {
+	inhibition, err := c.inhibit(ctx, "--inhibit-device="+uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inhibit device: %w", err)
+	}
+	return inhibition, nil
+}
+
+// Inhibition is held by a running mmcli --inhibit or --inhibit-device
+type Inhibition struct {
+	client *Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	args   []string
+	start  time.Time
+	stream io.ReadCloser
+	done   chan struct{}
+
+	mu    sync.Mutex
+	ended bool
+	err   error
+}
+
+// inhibit starts an inhibiting mmcli and waits until it confirms the inhibition
+func (c *Client) inhibit(ctx context.Context, args ...string) (*Inhibition, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	start := time.Now()
+	stream, err := c.stream(streamCtx, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	confirmed := false
+	reader := bufio.NewReader(stream)
+	for !confirmed {
+		line, err := reader.ReadString('\n')
+		confirmed = strings.HasPrefix(strings.TrimSpace(line), "successfully inhibited")
+		if err != nil && !confirmed {
+			break
+		}
+	}
+	if !confirmed {
+		err := stream.Close()
+		cancel()
+		c.logInvocation(ctx, args, time.Since(start), nil, err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err == nil {
+			err = errors.New("mmcli exited without confirming the inhibition")
+		}
+		return nil, streamError(args, err)
+	}
+
+	i := &Inhibition{client: c, ctx: ctx, cancel: cancel, args: args, start: start, stream: stream, done: make(chan struct{})}
+	go func() {
+		// mmcli may still print, which must not block it; the output ends
+		// when mmcli exits
+		io.Copy(io.Discard, reader)
+		i.end(streamCtx.Err() == nil)
+	}()
+	go func() {
+		select {
+		case <-streamCtx.Done():
+			i.end(false)
+		case <-i.done:
+		}
+	}()
+	return i, nil
+}
+
+// Release stops mmcli, which releases the inhibition. It returns
+// ErrInhibitionEnded if mmcli had already exited on its own; calling it again
+// returns the same result.
+func (i *Inhibition) Release() error {
+	return i.end(false)
+}
+
+// Done returns a channel that is closed once the inhibition ended, because
+// it was released, ctx was cancelled or mmcli exited on its own
+func (i *Inhibition) Done() <-chan struct{} {
+	return i.done
+}
+
+// Err returns nil while the inhibition is held or if it was released, and
+// an error matching ErrInhibitionEnded if mmcli exited on its own
+func (i *Inhibition) Err() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.err
+}
+
+// end stops mmcli once. exited tells that mmcli exited without being asked to.
+func (i *Inhibition) end(exited bool) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.ended {
+		return i.err
+	}
+	i.ended = true
+
+	i.cancel()
+	err := i.stream.Close()
+	i.client.logInvocation(i.ctx, i.args, time.Since(i.start), nil, err)
+	if err != nil {
+		// mmcli failed before it was stopped
+		i.err = fmt.Errorf("%w: %w", ErrInhibitionEnded, streamError(i.args, err))
+	} else if exited {
+		i.err = ErrInhibitionEnded
+	}
+	close(i.done)
+	return i.err
+}
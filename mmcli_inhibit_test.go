@@ -0,0 +1,173 @@
+package mmcli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// inhibitRunner plays back mmcli's output and, if it confirmed the
+// inhibition, keeps running until it is stopped or exits. A stderr makes
+// mmcli fail with exit status 1.
+type inhibitRunner struct {
+	scriptedRunner
+	output  string
+	stderr  string
+	exit    chan struct{}
+	args    chan []string
+	stopped chan struct{}
+}
+
+func (r *inhibitRunner) Stream(ctx context.Context, args ...string) (io.ReadCloser, error) {
+	r.args <- args
+	pr, pw := io.Pipe()
+	go func() {
+		io.WriteString(pw, r.output)
+		if strings.Contains(r.output, "successfully inhibited") {
+			select {
+			case <-ctx.Done():
+				close(r.stopped)
+			case <-r.exit:
+			}
+		}
+		pw.Close()
+	}()
+	return &inhibitStream{PipeReader: pr, stderr: r.stderr}, nil
+}
+
+type inhibitStream struct {
+	*io.PipeReader
+	stderr string
+}
+
+func (s *inhibitStream) Close() error {
+	s.PipeReader.Close()
+	if s.stderr != "" {
+		return &StreamExitError{ExitCode: 1, Stderr: []byte(s.stderr)}
+	}
+	return nil
+}
+
+func newInhibitRunner(output string) *inhibitRunner {
+	return &inhibitRunner{
+		scriptedRunner: scriptedRunner{responses: map[string]*Result{}},
+		output:         output,
+		exit:           make(chan struct{}),
+		args:           make(chan []string, 1),
+		stopped:        make(chan struct{}),
+	}
+}
+
+func waitStopped(t *testing.T, runner *inhibitRunner) {
+	t.Helper()
+	select {
+	case <-runner.stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for mmcli to stop")
+	}
+}
+
+func TestInhibit(t *testing.T) {
+	runner := newInhibitRunner("successfully inhibited modem /org/freedesktop/ModemManager1/Modem/0\ntype Ctrl+C to abort the inhibition and let ModemManager manage the device again\n")
+	client := NewClient(runner, WithVersion(Version{Major: 1, Minor: 20}))
+
+	inhibition, err := client.Inhibit(context.Background(), "0")
+	if err != nil {
+		t.Fatalf("Failed to inhibit modem: %v", err)
+	}
+	if args := strings.Join(<-runner.args, " "); args != "-m 0 --inhibit" {
+		t.Errorf("Unexpected invocation mmcli %s", args)
+	}
+	select {
+	case <-runner.stopped:
+		t.Fatal("Expected mmcli to keep running until released")
+	default:
+	}
+
+	if err := inhibition.Release(); err != nil {
+		t.Errorf("Failed to release the inhibition: %v", err)
+	}
+	waitStopped(t, runner)
+	if err := inhibition.Release(); err != nil {
+		t.Errorf("Expected releasing twice to succeed, got %v", err)
+	}
+	select {
+	case <-inhibition.Done():
+	default:
+		t.Error("Expected Done to be closed after releasing")
+	}
+	if err := inhibition.Err(); err != nil {
+		t.Errorf("Expected no error after releasing, got %v", err)
+	}
+}
+
+func TestInhibitDeviceReleasedByContext(t *testing.T) {
+	runner := newInhibitRunner("successfully inhibited device with uid '3f2a9c8d5b1e7f60'\n")
+	client := NewClient(runner, WithVersion(Version{Major: 1, Minor: 20}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	inhibition, err := client.InhibitDevice(ctx, "3f2a9c8d5b1e7f60")
+	if err != nil {
+		t.Fatalf("Failed to inhibit device: %v", err)
+	}
+	if args := strings.Join(<-runner.args, " "); args != "--inhibit-device=3f2a9c8d5b1e7f60" {
+		t.Errorf("Unexpected invocation mmcli %s", args)
+	}
+	cancel()
+	waitStopped(t, runner)
+	<-inhibition.Done()
+	if err := inhibition.Err(); err != nil {
+		t.Errorf("Expected no error after cancelling, got %v", err)
+	}
+}
+
+func TestInhibitEndsEarly(t *testing.T) {
+	runner := newInhibitRunner("successfully inhibited modem /org/freedesktop/ModemManager1/Modem/0\n")
+	client := NewClient(runner, WithVersion(Version{Major: 1, Minor: 20}))
+
+	inhibition, err := client.Inhibit(context.Background(), "0")
+	if err != nil {
+		t.Fatalf("Failed to inhibit modem: %v", err)
+	}
+	<-runner.args
+	if err := inhibition.Err(); err != nil {
+		t.Errorf("Expected no error while inhibited, got %v", err)
+	}
+
+	// mmcli exits with status 0, e.g. because the modem went away
+	close(runner.exit)
+	select {
+	case <-inhibition.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the inhibition to end")
+	}
+	if err := inhibition.Err(); !errors.Is(err, ErrInhibitionEnded) {
+		t.Errorf("Expected ErrInhibitionEnded, got %v", err)
+	}
+	if err := inhibition.Release(); !errors.Is(err, ErrInhibitionEnded) {
+		t.Errorf("Expected releasing to report ErrInhibitionEnded, got %v", err)
+	}
+}
+
+func TestInhibitFailure(t *testing.T) {
+	runner := newInhibitRunner("")
+	client := NewClient(runner, WithVersion(Version{Major: 1, Minor: 20}))
+	if _, err := client.Inhibit(context.Background(), "0"); err == nil {
+		t.Error("Expected an error when mmcli exits without confirmation")
+	}
+
+	runner = newInhibitRunner("")
+	runner.stderr = "error: couldn't find modem\n"
+	client = NewClient(runner, WithVersion(Version{Major: 1, Minor: 20}))
+	if _, err := client.Inhibit(context.Background(), "7"); !errors.Is(err, ErrModemNotFound) {
+		t.Errorf("Expected ErrModemNotFound, got %v", err)
+	}
+
+	client = NewClient(runner, WithVersion(Version{Major: 1, Minor: 12}))
+	if _, err := client.Inhibit(context.Background(), "0"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported before mmcli 1.14, got %v", err)
+	}
+}
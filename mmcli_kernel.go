@@ -0,0 +1,157 @@
+package mmcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// KernelAction tells whether a kernel device appeared or disappeared
+type KernelAction string
+
+// Kernel event actions
+const (
+	KernelActionAdd    KernelAction = "add"
+	KernelActionRemove KernelAction = "remove"
+)
+
+// KernelEvent is a device event reported to ModemManager on systems without
+// udev, where ModemManager runs with --no-udev or a filter policy that
+// requires reported kernel events
+type KernelEvent struct {
+	Action    KernelAction
+	Subsystem string // e.g. tty, net, usbmisc or wwan
+	Name      string // e.g. ttyUSB2, wwan0 or cdc-wdm0
+	// UID groups the ports of one modem. If empty, ModemManager derives the
+	// physical device from sysfs.
+	UID string
+}
+
+// String returns the event as passed to mmcli --report-kernel-event, e.g.
+// "action=add,subsystem=tty,name=ttyUSB2"
+func (e KernelEvent) String() string {
+	s := fmt.Sprintf("action=%s,subsystem=%s,name=%s", e.Action, e.Subsystem, e.Name)
+	if e.UID != "" {
+		s += ",uid=" + e.UID
+	}
+	return s
+}
+
+// validate checks that mmcli can parse the event
+func (e KernelEvent) validate() error {
+	if e.Action != KernelActionAdd && e.Action != KernelActionRemove {
+		return fmt.Errorf("unknown kernel event action %q", e.Action)
+	}
+	if e.Subsystem == "" || e.Name == "" {
+		return errors.New("kernel event needs a subsystem and a name")
+	}
+	for _, value := range []string{e.Subsystem, e.Name, e.UID} {
+		if strings.ContainsAny(value, `,="`) {
+			return fmt.Errorf("invalid kernel event value %q", value)
+		}
+	}
+	return nil
+}
+
+// ReportKernelEvent tells ModemManager about a device that appeared or
+// disappeared
+func ReportKernelEvent(event KernelEvent) error {
+	return ReportKernelEventContext(context.Background(), event)
+}
+
+// ReportKernelEventContext is like ReportKernelEvent but uses ctx to bound the mmcli invocation
+func ReportKernelEventContext(ctx context.Context, event KernelEvent) error {
+	return DefaultClient.ReportKernelEvent(ctx, event)
+}
+
+// ReportKernelEvent tells ModemManager about a device that appeared or
+// disappeared
+func (c *Client) ReportKernelEvent(ctx context.Context, event KernelEvent) error {
+	if err := event.validate(); err != nil {
+		return fmt.Errorf("failed to report kernel event: %w", err)
+	}
+	if _, err := c.run(ctx, "--report-kernel-event="+event.String()); err != nil {
+		return fmt.Errorf("failed to report kernel event: %w", err)
+	}
+	return nil
+}
+
+// kernelSubsystems are the device classes ModemManager uses for modem ports
+var kernelSubsystems = []string{"tty", "net", "usbmisc", "wwan"}
+
+// KernelDevices returns an add event for every device in /sys/class/tty,
+// net, usbmisc and wwan that is backed by hardware. Virtual devices such as
+// lo or the console ttys are skipped. Missing classes are not an error.
+func KernelDevices() ([]KernelEvent, error) {
+	return KernelDevicesIn("/sys/class")
+}
+
+// KernelDevicesIn is like KernelDevices but scans the device classes in
+// root, e.g. in a sysfs mounted elsewhere
+func KernelDevicesIn(root string) ([]KernelEvent, error) {
+	var events []KernelEvent
+	for _, subsystem := range kernelSubsystems {
+		entries, err := os.ReadDir(filepath.Join(root, subsystem))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s devices: %w", subsystem, err)
+		}
+
+		var names []string
+		for _, entry := range entries {
+			// only devices with a parent device are real hardware
+			if _, err := os.Stat(filepath.Join(root, subsystem, entry.Name(), "device")); err == nil {
+				names = append(names, entry.Name())
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			events = append(events, KernelEvent{Action: KernelActionAdd, Subsystem: subsystem, Name: name})
+		}
+	}
+	return events, nil
+}
+
+// ReportKernelDevices reports every device found by KernelDevices to
+// ModemManager, e.g. once at boot
+func ReportKernelDevices() error {
+	return ReportKernelDevicesContext(context.Background())
+}
+
+// ReportKernelDevicesContext is like ReportKernelDevices but uses ctx to bound the mmcli invocations
+func ReportKernelDevicesContext(ctx context.Context) error {
+	return DefaultClient.ReportKernelDevices(ctx)
+}
+
+// ReportKernelDevices reports every device found by KernelDevices to
+// ModemManager. A device that cannot be reported does not stop the others;
+// all failures are returned together.
+func (c *Client) ReportKernelDevices(ctx context.Context) error {
+	return c.ReportKernelDevicesIn(ctx, "/sys/class")
+}
+
+// ReportKernelDevicesIn is like ReportKernelDevices but reports the devices
+// found by KernelDevicesIn(root)
+func (c *Client) ReportKernelDevicesIn(ctx context.Context, root string) error {
+	events, err := KernelDevicesIn(root)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, event := range events {
+		if err := c.ReportKernelEvent(ctx, event); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			errs = append(errs, fmt.Errorf("%s %s: %w", event.Subsystem, event.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,103 @@
+package mmcli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReportKernelEvent(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		`--report-kernel-event=action=add,subsystem=tty,name=ttyUSB2`:                {},
+		`--report-kernel-event=action=remove,subsystem=net,name=wwan0,uid=/sys/usb1`: {},
+	}}
+	client := NewClient(runner)
+	ctx := context.Background()
+
+	if err := client.ReportKernelEvent(ctx, KernelEvent{Action: KernelActionAdd, Subsystem: "tty", Name: "ttyUSB2"}); err != nil {
+		t.Errorf("Failed to report kernel event: %v", err)
+	}
+	if err := client.ReportKernelEvent(ctx, KernelEvent{Action: KernelActionRemove, Subsystem: "net", Name: "wwan0", UID: "/sys/usb1"}); err != nil {
+		t.Errorf("Failed to report kernel event: %v", err)
+	}
+
+	for _, event := range []KernelEvent{
+		{Action: "change", Subsystem: "tty", Name: "ttyUSB2"},
+		{Action: KernelActionAdd, Subsystem: "tty"},
+		{Action: KernelActionAdd, Subsystem: "tty", Name: "ttyUSB2,name=ttyUSB3"},
+	} {
+		if err := client.ReportKernelEvent(ctx, event); err == nil {
+			t.Errorf("Expected an error for %+v", event)
+		}
+	}
+	if len(runner.calls) != 2 {
+		t.Errorf("Expected invalid events not to reach mmcli, got %v", runner.calls)
+	}
+}
+
+// fakeSysClass creates class/name entries, with a device link unless virtual
+func fakeSysClass(t *testing.T, devices map[string]bool) string {
+	t.Helper()
+	root := t.TempDir()
+	for device, virtual := range devices {
+		dir := filepath.Join(root, device)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if !virtual {
+			if err := os.Symlink(root, filepath.Join(dir, "device")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return root
+}
+
+func TestKernelDevices(t *testing.T) {
+	root := fakeSysClass(t, map[string]bool{
+		"tty/ttyUSB2":      false,
+		"tty/ttyUSB0":      false,
+		"tty/tty0":         true,
+		"net/wwan0":        false,
+		"net/lo":           true,
+		"usbmisc/cdc-wdm0": false,
+		"input/event0":     false,
+		"wwan/wwan0at0":    false,
+		"wwan/wwan0qmi0":   false,
+	})
+	events, err := KernelDevicesIn(root)
+	if err != nil {
+		t.Fatalf("Failed to scan devices: %v", err)
+	}
+	var got []string
+	for _, event := range events {
+		got = append(got, event.String())
+	}
+	expected := []string{
+		"action=add,subsystem=tty,name=ttyUSB0",
+		"action=add,subsystem=tty,name=ttyUSB2",
+		"action=add,subsystem=net,name=wwan0",
+		"action=add,subsystem=usbmisc,name=cdc-wdm0",
+		"action=add,subsystem=wwan,name=wwan0at0",
+		"action=add,subsystem=wwan,name=wwan0qmi0",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// the scan goes on after a failed report
+	runner := &scriptedRunner{responses: map[string]*Result{}}
+	for _, event := range expected[1:] {
+		runner.responses["--report-kernel-event="+event] = &Result{}
+	}
+	err = NewClient(runner).ReportKernelDevicesIn(context.Background(), root)
+	if err == nil || !strings.Contains(err.Error(), "tty ttyUSB0") {
+		t.Errorf("Expected an error for ttyUSB0, got %v", err)
+	}
+	if len(runner.calls) != len(expected) {
+		t.Errorf("Expected every device to be reported, got %v", runner.calls)
+	}
+}
@@ -0,0 +1,221 @@
+package mmcli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format selects the machine-readable output format requested from mmcli
+type Format int
+
+const (
+	// FormatJSON requests JSON output (-J)
+	FormatJSON Format = iota
+	// FormatKeyValue requests key-value output (-K), for ModemManager builds
+	// with broken or incomplete JSON output
+	FormatKeyValue
+)
+
+// flag returns the mmcli option selecting the format
+func (f Format) flag() string {
+	if f == FormatKeyValue {
+		return "-K"
+	}
+	return "-J"
+}
+
+// String returns the name of the format
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatKeyValue:
+		return "keyvalue"
+	default:
+		return "Format(" + strconv.Itoa(int(f)) + ")"
+	}
+}
+
+// keyValueLineRe matches a "key : value" line of mmcli -K output. Keys are
+// padded with spaces so that the values line up.
+var keyValueLineRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9.\-\[\]]*)\s*: ?(.*)$`)
+
+// keyValueArrayRe matches the element keys of an array, e.g. "modem.generic.drivers.value[1]"
+var keyValueArrayRe = regexp.MustCompile(`^(.+)\.value\[(\d+)\]$`)
+
+// dottedKeys lists keys that mmcli prints with dots in JSON output instead of
+// as nested objects
+var dottedKeys = []string{
+	"modem.messaging.sms",
+	"modem.3gpp.profile-manager.list",
+}
+
+// ParseKeyValue parses mmcli -K output into a ModemManager struct
+func ParseKeyValue(data []byte) (*ModemManager, error) {
+	var mm ModemManager
+	if err := unmarshalKeyValue(data, &mm); err != nil {
+		return nil, fmt.Errorf("failed to parse mmcli output: %w", err)
+	}
+	return &mm, nil
+}
+
+// unmarshalKeyValue decodes mmcli -K output into v, using the same struct
+// tags as for JSON output
+func unmarshalKeyValue(data []byte, v interface{}) error {
+	js, err := KeyValueToJSON(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, v)
+}
+
+// KeyValueToJSON converts mmcli -K output to the JSON document mmcli -J
+// prints for the same query
+func KeyValueToJSON(data []byte) ([]byte, error) {
+	root := map[string]interface{}{}
+
+	var lastValue *string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		m := keyValueLineRe.FindStringSubmatch(line)
+		if m == nil {
+			// values spanning multiple lines, e.g. the PCO list
+			if lastValue == nil {
+				return nil, fmt.Errorf("unexpected line in key-value output: %q", line)
+			}
+			*lastValue += "\n" + strings.TrimSpace(line)
+			continue
+		}
+
+		var err error
+		lastValue, err = setKeyValue(root, m[1], strings.TrimRight(m[2], " "))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read key-value output: %w", err)
+	}
+
+	return json.Marshal(root)
+}
+
+// setKeyValue stores a single key-value pair in the document and returns a
+// pointer to the stored string, or nil for array lengths
+func setKeyValue(root map[string]interface{}, key, value string) (*string, error) {
+	if path, ok := strings.CutSuffix(key, ".length"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length for %s: %q", path, value)
+		}
+		_, err = arrayAt(root, path, n)
+		return nil, err
+	}
+
+	if m := keyValueArrayRe.FindStringSubmatch(key); m != nil {
+		index, _ := strconv.Atoi(m[2])
+		if index < 1 {
+			return nil, fmt.Errorf("invalid array index in key %s", key)
+		}
+		arr, err := arrayAt(root, m[1], index)
+		if err != nil {
+			return nil, err
+		}
+		arr.values[index-1] = value
+		return &arr.values[index-1], nil
+	}
+
+	parent, name, err := parentOf(root, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := parent[name]; exists {
+		return nil, fmt.Errorf("conflicting key %s in key-value output", key)
+	}
+	s := value
+	parent[name] = &s
+	return &s, nil
+}
+
+// keyValueArray is an array under construction; its elements are filled in
+// place as the value[N] keys are read
+type keyValueArray struct {
+	values []string
+}
+
+// MarshalJSON encodes the array as a JSON array of strings
+func (a *keyValueArray) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.values)
+}
+
+// arrayAt returns the array at path, creating it or growing it to at least n elements
+func arrayAt(root map[string]interface{}, path string, n int) (*keyValueArray, error) {
+	parent, name, err := parentOf(root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	arr, ok := parent[name].(*keyValueArray)
+	if !ok {
+		if _, exists := parent[name]; exists {
+			return nil, fmt.Errorf("conflicting key %s in key-value output", path)
+		}
+		arr = &keyValueArray{values: []string{}}
+		parent[name] = arr
+	}
+	for len(arr.values) < n {
+		arr.values = append(arr.values, "")
+	}
+	return arr, nil
+}
+
+// parentOf returns the object holding the last element of a dotted key,
+// creating intermediate objects as needed
+func parentOf(root map[string]interface{}, key string) (map[string]interface{}, string, error) {
+	var parts []string
+	for _, dotted := range dottedKeys {
+		if key == dotted || strings.HasPrefix(key, dotted+".") {
+			parts = append([]string{dotted}, splitKey(strings.TrimPrefix(key[len(dotted):], "."))...)
+			break
+		}
+	}
+	if parts == nil {
+		parts = splitKey(key)
+	}
+
+	obj := root
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := obj[part]
+		if !ok {
+			child := map[string]interface{}{}
+			obj[part] = child
+			obj = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("conflicting key %s in key-value output", key)
+		}
+		obj = child
+	}
+	return obj, parts[len(parts)-1], nil
+}
+
+func splitKey(key string) []string {
+	if key == "" {
+		return nil
+	}
+	return strings.Split(key, ".")
+}
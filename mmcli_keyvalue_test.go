@@ -0,0 +1,109 @@
+package mmcli
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestKeyValueToJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "nested values and arrays",
+			input: `modem.dbus-path                    : /org/freedesktop/ModemManager1/Modem/0
+modem.generic.drivers.length       : 2
+modem.generic.drivers.value[1]     : option
+modem.generic.drivers.value[2]     : qmi_wwan
+modem.generic.bearers.length       : 0
+modem.generic.signal-quality.value : 67
+`,
+			want: `{"modem": {"dbus-path": "/org/freedesktop/ModemManager1/Modem/0", "generic": {
+				"drivers": ["option", "qmi_wwan"], "bearers": [], "signal-quality": {"value": "67"}}}}`,
+		},
+		{
+			name: "dotted key",
+			input: `modem.messaging.sms.length   : 1
+modem.messaging.sms.value[1] : /org/freedesktop/ModemManager1/SMS/0
+`,
+			want: `{"modem.messaging.sms": ["/org/freedesktop/ModemManager1/SMS/0"]}`,
+		},
+		{
+			name: "values containing colons and empty values",
+			input: `modem.time.network-time : 2024-03-15T10:15:30Z
+modem.time.local        :
+`,
+			want: `{"modem": {"time": {"network-time": "2024-03-15T10:15:30Z", "local": ""}}}`,
+		},
+		{
+			name: "multi-line value",
+			input: `modem.3gpp.pco : 0: (complete) 27
+    1: (partial) 28
+modem.3gpp.imei : 867698041234567
+`,
+			want: `{"modem": {"3gpp": {"pco": "0: (complete) 27\n1: (partial) 28", "imei": "867698041234567"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := KeyValueToJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Failed to convert key-value output: %v", err)
+			}
+
+			var gotValue, wantValue interface{}
+			if err := json.Unmarshal(got, &gotValue); err != nil {
+				t.Fatalf("Invalid JSON %s: %v", got, err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &wantValue); err != nil {
+				t.Fatalf("Invalid expected JSON: %v", err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestKeyValueToJSONConflict(t *testing.T) {
+	input := `modem.generic.state       : connected
+modem.generic.state.extra : oops
+`
+	if _, err := KeyValueToJSON([]byte(input)); err == nil {
+		t.Error("Expected an error for conflicting keys")
+	}
+}
+
+func TestClientKeyValueFormat(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-K -L": {Stdout: []byte(`modem-list.length   : 1
+modem-list.value[1] : /org/freedesktop/ModemManager1/Modem/2
+`)},
+		"-m 2 -K": {Stdout: []byte(`modem.generic.state                 : connected
+modem.generic.signal-quality.value  : 54
+modem.generic.signal-quality.recent : yes
+`)},
+	}}
+	client := NewClient(runner, WithFormat(FormatKeyValue))
+
+	id, err := client.GetFirstModemID(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to get first modem ID: %v", err)
+	}
+	if id != "2" {
+		t.Errorf("Expected modem ID 2, got %s", id)
+	}
+
+	mm, err := client.GetModemDetails(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to get modem details: %v", err)
+	}
+	if strength, _ := mm.SignalStrength(); !mm.IsConnected() || strength != 54 {
+		t.Errorf("Expected a connected modem with signal 54, got %+v", mm.Modem.Generic)
+	}
+}
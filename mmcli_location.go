@@ -1,9 +1,8 @@
 package mmcli
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os/exec"
 )
 
 // LocationStatus represents the status of location gathering
@@ -12,6 +11,9 @@ type LocationStatus struct {
 	Enabled      []string `json:"enabled"`
 	Signals      string   `json:"signals"`
 	GPS          GPSInfo  `json:"gps"`
+
+	// Typed values decoded from the strings above
+	SignalsEnabled bool `json:"-"`
 }
 
 // GPSInfo contains GPS-specific information
@@ -20,6 +22,9 @@ type GPSInfo struct {
 	AssistanceServers []string `json:"assistance-servers"`
 	RefreshRate       string   `json:"refresh-rate"`
 	SuplServer        string   `json:"supl-server"`
+
+	// Typed values decoded from the strings above
+	RefreshRateSeconds *int `json:"-"` // nil if unknown
 }
 
 // LocationInfo represents location information from various sources
@@ -36,6 +41,11 @@ type ThreeGPPLocation struct {
 	LAC string `json:"lac"`
 	CID string `json:"cid"`
 	TAC string `json:"tac"`
+
+	// Typed values decoded from the hexadecimal strings above, nil if unknown
+	LACNumber *uint64 `json:"-"`
+	CIDNumber *uint64 `json:"-"`
+	TACNumber *uint64 `json:"-"`
 }
 
 // GPSLocation contains GPS-specific location information
@@ -45,17 +55,36 @@ type GPSLocation struct {
 	Altitude  string   `json:"altitude"`
 	UTC       string   `json:"utc"`
 	NMEA      []string `json:"nmea"`
+
+	// Typed values decoded from the strings above, nil without a fix
+	LatitudeDegrees  *float64 `json:"-"`
+	LongitudeDegrees *float64 `json:"-"`
+	AltitudeMeters   *float64 `json:"-"`
 }
 
 // CDMALocation contains CDMA-specific location information
 type CDMALocation struct {
 	Latitude  string `json:"latitude"`
 	Longitude string `json:"longitude"`
+
+	// Typed values decoded from the strings above, nil if unknown
+	LatitudeDegrees  *float64 `json:"-"`
+	LongitudeDegrees *float64 `json:"-"`
 }
 
 // GetLocationStatus returns the current status of location gathering
 func GetLocationStatus(modemID string) (*LocationStatus, error) {
-	out, err := exec.Command("mmcli", "-m", modemID, "--location-status", "-J").Output()
+	return GetLocationStatusContext(context.Background(), modemID)
+}
+
+// GetLocationStatusContext is like GetLocationStatus but uses ctx to bound the mmcli invocation
+func GetLocationStatusContext(ctx context.Context, modemID string) (*LocationStatus, error) {
+	return DefaultClient.GetLocationStatus(ctx, modemID)
+}
+
+// GetLocationStatus returns the current status of location gathering
+func (c *Client) GetLocationStatus(ctx context.Context, modemID string) (*LocationStatus, error) {
+	out, err := c.run(ctx, "-m", modemID, "--location-status", c.output())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get location status: %w", err)
 	}
@@ -66,7 +95,7 @@ func GetLocationStatus(modemID string) (*LocationStatus, error) {
 		} `json:"modem"`
 	}
 
-	if err := json.Unmarshal(out, &response); err != nil {
+	if err := c.unmarshal(out, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse location status: %w", err)
 	}
 
@@ -75,7 +104,17 @@ func GetLocationStatus(modemID string) (*LocationStatus, error) {
 
 // GetLocation returns the current location information
 func GetLocation(modemID string) (*LocationInfo, error) {
-	out, err := exec.Command("mmcli", "-m", modemID, "--location-get", "-J").Output()
+	return GetLocationContext(context.Background(), modemID)
+}
+
+// GetLocationContext is like GetLocation but uses ctx to bound the mmcli invocation
+func GetLocationContext(ctx context.Context, modemID string) (*LocationInfo, error) {
+	return DefaultClient.GetLocation(ctx, modemID)
+}
+
+// GetLocation returns the current location information
+func (c *Client) GetLocation(ctx context.Context, modemID string) (*LocationInfo, error) {
+	out, err := c.run(ctx, "-m", modemID, "--location-get", c.output())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get location: %w", err)
 	}
@@ -86,7 +125,7 @@ func GetLocation(modemID string) (*LocationInfo, error) {
 		} `json:"modem"`
 	}
 
-	if err := json.Unmarshal(out, &response); err != nil {
+	if err := c.unmarshal(out, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse location info: %w", err)
 	}
 
@@ -95,6 +134,16 @@ func GetLocation(modemID string) (*LocationInfo, error) {
 
 // EnableLocationGathering enables a specific location gathering method
 func EnableLocationGathering(modemID string, method string) error {
+	return EnableLocationGatheringContext(context.Background(), modemID, method)
+}
+
+// EnableLocationGatheringContext is like EnableLocationGathering but uses ctx to bound the mmcli invocation
+func EnableLocationGatheringContext(ctx context.Context, modemID string, method string) error {
+	return DefaultClient.EnableLocationGathering(ctx, modemID, method)
+}
+
+// EnableLocationGathering enables a specific location gathering method
+func (c *Client) EnableLocationGathering(ctx context.Context, modemID string, method string) error {
 	var option string
 	switch method {
 	case "3gpp":
@@ -115,7 +164,7 @@ func EnableLocationGathering(modemID string, method string) error {
 		return fmt.Errorf("unsupported location method: %s", method)
 	}
 
-	_, err := exec.Command("mmcli", "-m", modemID, option).Output()
+	_, err := c.run(ctx, "-m", modemID, option)
 	if err != nil {
 		return fmt.Errorf("failed to enable location gathering (%s): %w", method, err)
 	}
@@ -125,6 +174,16 @@ func EnableLocationGathering(modemID string, method string) error {
 
 // DisableLocationGathering disables a specific location gathering method
 func DisableLocationGathering(modemID string, method string) error {
+	return DisableLocationGatheringContext(context.Background(), modemID, method)
+}
+
+// DisableLocationGatheringContext is like DisableLocationGathering but uses ctx to bound the mmcli invocation
+func DisableLocationGatheringContext(ctx context.Context, modemID string, method string) error {
+	return DefaultClient.DisableLocationGathering(ctx, modemID, method)
+}
+
+// DisableLocationGathering disables a specific location gathering method
+func (c *Client) DisableLocationGathering(ctx context.Context, modemID string, method string) error {
 	var option string
 	switch method {
 	case "3gpp":
@@ -145,7 +204,7 @@ func DisableLocationGathering(modemID string, method string) error {
 		return fmt.Errorf("unsupported location method: %s", method)
 	}
 
-	_, err := exec.Command("mmcli", "-m", modemID, option).Output()
+	_, err := c.run(ctx, "-m", modemID, option)
 	if err != nil {
 		return fmt.Errorf("failed to disable location gathering (%s): %w", method, err)
 	}
@@ -155,7 +214,17 @@ func DisableLocationGathering(modemID string, method string) error {
 
 // SetSuplServer sets the SUPL server address for A-GPS
 func SetSuplServer(modemID string, address string) error {
-	_, err := exec.Command("mmcli", "-m", modemID, "--location-set-supl-server="+address).Output()
+	return SetSuplServerContext(context.Background(), modemID, address)
+}
+
+// SetSuplServerContext is like SetSuplServer but uses ctx to bound the mmcli invocation
+func SetSuplServerContext(ctx context.Context, modemID string, address string) error {
+	return DefaultClient.SetSuplServer(ctx, modemID, address)
+}
+
+// SetSuplServer sets the SUPL server address for A-GPS
+func (c *Client) SetSuplServer(ctx context.Context, modemID string, address string) error {
+	_, err := c.run(ctx, "-m", modemID, "--location-set-supl-server="+address)
 	if err != nil {
 		return fmt.Errorf("failed to set SUPL server: %w", err)
 	}
@@ -165,7 +234,17 @@ func SetSuplServer(modemID string, address string) error {
 
 // SetGpsRefreshRate sets the GPS refresh rate in seconds
 func SetGpsRefreshRate(modemID string, rate int) error {
-	_, err := exec.Command("mmcli", "-m", modemID, fmt.Sprintf("--location-set-gps-refresh-rate=%d", rate)).Output()
+	return SetGpsRefreshRateContext(context.Background(), modemID, rate)
+}
+
+// SetGpsRefreshRateContext is like SetGpsRefreshRate but uses ctx to bound the mmcli invocation
+func SetGpsRefreshRateContext(ctx context.Context, modemID string, rate int) error {
+	return DefaultClient.SetGpsRefreshRate(ctx, modemID, rate)
+}
+
+// SetGpsRefreshRate sets the GPS refresh rate in seconds
+func (c *Client) SetGpsRefreshRate(ctx context.Context, modemID string, rate int) error {
+	_, err := c.run(ctx, "-m", modemID, fmt.Sprintf("--location-set-gps-refresh-rate=%d", rate))
 	if err != nil {
 		return fmt.Errorf("failed to set GPS refresh rate: %w", err)
 	}
@@ -175,7 +254,17 @@ func SetGpsRefreshRate(modemID string, rate int) error {
 
 // EnableLocationSignals enables location update signaling in DBus property
 func EnableLocationSignals(modemID string) error {
-	_, err := exec.Command("mmcli", "-m", modemID, "--location-set-enable-signal").Output()
+	return EnableLocationSignalsContext(context.Background(), modemID)
+}
+
+// EnableLocationSignalsContext is like EnableLocationSignals but uses ctx to bound the mmcli invocation
+func EnableLocationSignalsContext(ctx context.Context, modemID string) error {
+	return DefaultClient.EnableLocationSignals(ctx, modemID)
+}
+
+// EnableLocationSignals enables location update signaling in DBus property
+func (c *Client) EnableLocationSignals(ctx context.Context, modemID string) error {
+	_, err := c.run(ctx, "-m", modemID, "--location-set-enable-signal")
 	if err != nil {
 		return fmt.Errorf("failed to enable location signals: %w", err)
 	}
@@ -185,10 +274,41 @@ func EnableLocationSignals(modemID string) error {
 
 // DisableLocationSignals disables location update signaling in DBus property
 func DisableLocationSignals(modemID string) error {
-	_, err := exec.Command("mmcli", "-m", modemID, "--location-set-disable-signal").Output()
+	return DisableLocationSignalsContext(context.Background(), modemID)
+}
+
+// DisableLocationSignalsContext is like DisableLocationSignals but uses ctx to bound the mmcli invocation
+func DisableLocationSignalsContext(ctx context.Context, modemID string) error {
+	return DefaultClient.DisableLocationSignals(ctx, modemID)
+}
+
+// DisableLocationSignals disables location update signaling in DBus property
+func (c *Client) DisableLocationSignals(ctx context.Context, modemID string) error {
+	_, err := c.run(ctx, "-m", modemID, "--location-set-disable-signal")
 	if err != nil {
 		return fmt.Errorf("failed to disable location signals: %w", err)
 	}
 
 	return nil
 }
+
+// InjectAssistanceData injects A-GPS assistance data (e.g. an XTRA file) into
+// the GNSS module. Requires mmcli 1.10 or newer.
+func InjectAssistanceData(modemID string, path string) error {
+	return InjectAssistanceDataContext(context.Background(), modemID, path)
+}
+
+// InjectAssistanceDataContext is like InjectAssistanceData but uses ctx to bound the mmcli invocation
+func InjectAssistanceDataContext(ctx context.Context, modemID string, path string) error {
+	return DefaultClient.InjectAssistanceData(ctx, modemID, path)
+}
+
+// InjectAssistanceData injects A-GPS assistance data (e.g. an XTRA file) into the GNSS module
+func (c *Client) InjectAssistanceData(ctx context.Context, modemID string, path string) error {
+	_, err := c.run(ctx, "-m", modemID, "--location-inject-assistance-data="+path)
+	if err != nil {
+		return fmt.Errorf("failed to inject assistance data: %w", err)
+	}
+
+	return nil
+}
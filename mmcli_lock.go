@@ -0,0 +1,167 @@
+package mmcli
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Lock is a lock that prevents a modem or SIM from being used until it is
+// unlocked with a PIN or PUK. The values match MMModemLock.
+type Lock uint32
+
+// Locks
+const (
+	LockUnknown     Lock = 0
+	LockNone        Lock = 1
+	LockSimPin      Lock = 2
+	LockSimPin2     Lock = 3
+	LockSimPuk      Lock = 4
+	LockSimPuk2     Lock = 5
+	LockPhSpPin     Lock = 6
+	LockPhSpPuk     Lock = 7
+	LockPhNetPin    Lock = 8
+	LockPhNetPuk    Lock = 9
+	LockPhSimPin    Lock = 10
+	LockPhCorpPin   Lock = 11
+	LockPhCorpPuk   Lock = 12
+	LockPhFsimPin   Lock = 13
+	LockPhFsimPuk   Lock = 14
+	LockPhNetsubPin Lock = 15
+	LockPhNetsubPuk Lock = 16
+)
+
+var lockNames = map[Lock]string{
+	LockUnknown:     "unknown",
+	LockNone:        "none",
+	LockSimPin:      "sim-pin",
+	LockSimPin2:     "sim-pin2",
+	LockSimPuk:      "sim-puk",
+	LockSimPuk2:     "sim-puk2",
+	LockPhSpPin:     "ph-sp-pin",
+	LockPhSpPuk:     "ph-sp-puk",
+	LockPhNetPin:    "ph-net-pin",
+	LockPhNetPuk:    "ph-net-puk",
+	LockPhSimPin:    "ph-sim-pin",
+	LockPhCorpPin:   "ph-corp-pin",
+	LockPhCorpPuk:   "ph-corp-puk",
+	LockPhFsimPin:   "ph-fsim-pin",
+	LockPhFsimPuk:   "ph-fsim-puk",
+	LockPhNetsubPin: "ph-netsub-pin",
+	LockPhNetsubPuk: "ph-netsub-puk",
+}
+
+// ParseLock parses a lock as printed by mmcli, e.g. "sim-pin". mmcli prints
+// "--" when the lock state is not known yet.
+func ParseLock(s string) (Lock, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "--" {
+		return LockUnknown, nil
+	}
+	for lock, name := range lockNames {
+		if name == s {
+			return lock, nil
+		}
+	}
+	return LockUnknown, fmt.Errorf("unknown lock %q", s)
+}
+
+// String returns the lock as printed by mmcli
+func (l Lock) String() string {
+	if name, ok := lockNames[l]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// IsLocked reports whether l is an actual lock, i.e. neither none nor unknown
+func (l Lock) IsLocked() bool {
+	return l != LockNone && l != LockUnknown
+}
+
+// IsSIM reports whether l is a lock of the SIM card rather than of the modem
+func (l Lock) IsSIM() bool {
+	switch l {
+	case LockSimPin, LockSimPin2, LockSimPuk, LockSimPuk2:
+		return true
+	}
+	return false
+}
+
+// IsPUK reports whether l must be unlocked with a PUK
+func (l Lock) IsPUK() bool {
+	return strings.HasSuffix(l.String(), "-puk") || l == LockSimPuk2
+}
+
+// unlockRetryRe matches an unlock retries entry, e.g. "sim-pin (3)"
+var unlockRetryRe = regexp.MustCompile(`^\s*([a-z0-9-]+)\s*\((\d+)\)\s*$`)
+
+// UnlockRetries holds the remaining unlock attempts per lock
+type UnlockRetries map[Lock]int
+
+// ParseUnlockRetries parses unlock retries as printed by mmcli, e.g.
+// []string{"sim-pin (3)", "sim-puk (10)"}. Entries mmcli printed in an
+// unexpected format are reported in the error, all others are kept.
+func ParseUnlockRetries(entries []string) (UnlockRetries, error) {
+	retries := make(UnlockRetries)
+	var invalid []string
+	for _, entry := range entries {
+		if entry == "" || entry == "--" {
+			continue
+		}
+		m := unlockRetryRe.FindStringSubmatch(entry)
+		if m == nil {
+			invalid = append(invalid, entry)
+			continue
+		}
+		lock, err := ParseLock(m[1])
+		if err != nil {
+			invalid = append(invalid, entry)
+			continue
+		}
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			invalid = append(invalid, entry)
+			continue
+		}
+		retries[lock] = n
+	}
+	if len(invalid) > 0 {
+		return retries, fmt.Errorf("invalid unlock retries %q", invalid)
+	}
+	return retries, nil
+}
+
+// Get returns the remaining attempts for a lock. known is false if the modem
+// did not report retries for the lock, in which case attempts is 0 but does
+// not mean that the lock is blocked.
+func (r UnlockRetries) Get(lock Lock) (attempts int, known bool) {
+	attempts, known = r[lock]
+	return attempts, known
+}
+
+// Strings returns the retries as printed by mmcli, ordered by lock
+func (r UnlockRetries) Strings() []string {
+	var out []string
+	for lock := LockUnknown; lock <= LockPhNetsubPuk; lock++ {
+		if n, ok := r[lock]; ok {
+			out = append(out, fmt.Sprintf("%s (%d)", lock, n))
+		}
+	}
+	return out
+}
+
+// UnlockRequired returns the lock that must be unlocked before the modem can
+// be used, LockNone if there is none, or LockUnknown if it is not known
+func (mm *ModemManager) UnlockRequired() Lock {
+	lock, _ := ParseLock(mm.Modem.Generic.UnlockRequired)
+	return lock
+}
+
+// UnlockRetries returns the remaining unlock attempts reported by the modem.
+// Entries this package cannot parse are ignored.
+func (mm *ModemManager) UnlockRetries() UnlockRetries {
+	retries, _ := ParseUnlockRetries(mm.Modem.Generic.UnlockRetries)
+	return retries
+}
@@ -0,0 +1,113 @@
+package mmcli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLock(t *testing.T) {
+	for lock := LockUnknown; lock <= LockPhNetsubPuk; lock++ {
+		got, err := ParseLock(lock.String())
+		if err != nil {
+			t.Errorf("ParseLock(%q): %v", lock, err)
+			continue
+		}
+		if got != lock {
+			t.Errorf("ParseLock(%q) = %d, expected %d", lock, got, lock)
+		}
+	}
+
+	if lock, err := ParseLock("--"); err != nil || lock != LockUnknown {
+		t.Errorf("Expected unknown for --, got %s, %v", lock, err)
+	}
+	if _, err := ParseLock("sim-pin3"); err == nil {
+		t.Error("Expected an error for an unknown lock")
+	}
+}
+
+func TestLockHelpers(t *testing.T) {
+	tests := []struct {
+		lock   Lock
+		locked bool
+		sim    bool
+		puk    bool
+	}{
+		{LockUnknown, false, false, false},
+		{LockNone, false, false, false},
+		{LockSimPin, true, true, false},
+		{LockSimPin2, true, true, false},
+		{LockSimPuk, true, true, true},
+		{LockSimPuk2, true, true, true},
+		{LockPhNetPin, true, false, false},
+		{LockPhNetPuk, true, false, true},
+		{LockPhSimPin, true, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.lock.IsLocked(); got != tt.locked {
+			t.Errorf("%s: IsLocked() = %v, expected %v", tt.lock, got, tt.locked)
+		}
+		if got := tt.lock.IsSIM(); got != tt.sim {
+			t.Errorf("%s: IsSIM() = %v, expected %v", tt.lock, got, tt.sim)
+		}
+		if got := tt.lock.IsPUK(); got != tt.puk {
+			t.Errorf("%s: IsPUK() = %v, expected %v", tt.lock, got, tt.puk)
+		}
+	}
+}
+
+func TestParseUnlockRetries(t *testing.T) {
+	retries, err := ParseUnlockRetries([]string{"sim-pin (0)", "sim-puk (10)", "sim-pin2 (3)", "sim-puk2 (10)"})
+	if err != nil {
+		t.Fatalf("Failed to parse unlock retries: %v", err)
+	}
+
+	if n, known := retries.Get(LockSimPin); n != 0 || !known {
+		t.Errorf("Expected sim-pin blocked with 0 known retries, got %d, %v", n, known)
+	}
+	if n, known := retries.Get(LockSimPin2); n != 3 || !known {
+		t.Errorf("Expected 3 sim-pin2 retries, got %d, %v", n, known)
+	}
+	if n, known := retries.Get(LockPhNetPin); n != 0 || known {
+		t.Errorf("Expected unknown ph-net-pin retries, got %d, %v", n, known)
+	}
+
+	expected := []string{"sim-pin (0)", "sim-pin2 (3)", "sim-puk (10)", "sim-puk2 (10)"}
+	if !reflect.DeepEqual(retries.Strings(), expected) {
+		t.Errorf("Expected %v, got %v", expected, retries.Strings())
+	}
+
+	retries, err = ParseUnlockRetries([]string{"sim-pin (3)", "sim-pin", "bogus (1)", "--"})
+	if err == nil {
+		t.Error("Expected an error for invalid entries")
+	}
+	if len(retries) != 1 || retries[LockSimPin] != 3 {
+		t.Errorf("Expected the valid entry to be kept, got %v", retries)
+	}
+}
+
+func TestModemManagerLocks(t *testing.T) {
+	var mm ModemManager
+	mm.Modem.Generic.UnlockRequired = "sim-puk"
+	mm.Modem.Generic.UnlockRetries = []string{"sim-pin2 (3)", "sim-pin (0)", "sim-puk (9)"}
+
+	if mm.UnlockRequired() != LockSimPuk {
+		t.Errorf("Expected sim-puk, got %s", mm.UnlockRequired())
+	}
+	if !mm.IsSimLocked() {
+		t.Error("Expected SIM to be locked")
+	}
+
+	// sim-pin2 is listed first and must not be mistaken for sim-pin
+	if n := mm.RemainingUnlockRetries("sim-pin"); n != 0 {
+		t.Errorf("Expected 0 sim-pin retries, got %d", n)
+	}
+	if n := mm.RemainingUnlockRetries("sim-puk"); n != 9 {
+		t.Errorf("Expected 9 sim-puk retries, got %d", n)
+	}
+
+	mm.Modem.Generic.UnlockRequired = "--"
+	if mm.UnlockRequired() != LockUnknown || mm.IsSimLocked() {
+		t.Errorf("Expected an unknown lock, got %s", mm.UnlockRequired())
+	}
+}
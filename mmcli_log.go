@@ -0,0 +1,114 @@
+package mmcli
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redacted replaces secret values in logged arguments
+const redacted = "REDACTED"
+
+// sensitiveKeys lists keys of key=value settings strings whose values are
+// never logged, e.g. in --simple-connect="apn=internet,password=secret"
+var sensitiveKeys = map[string]bool{
+	"password": true,
+	"pin":      true,
+	"puk":      true,
+	"text":     true,
+}
+
+// sensitiveOptions lists mmcli options whose values are never logged
+var sensitiveOptions = map[string]bool{
+	"--pin":        true,
+	"--puk":        true,
+	"--change-pin": true,
+}
+
+// settingsKeyRe matches the start of a key in a key=value settings string
+var settingsKeyRe = regexp.MustCompile(`(?:^|,)([a-z0-9-]+)=`)
+
+// WithLogger logs every mmcli invocation made by the client with its
+// command, duration, exit status and error. Passwords, PINs, PUKs and SMS
+// texts are redacted from the logged command.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// RedactArgs returns a copy of mmcli arguments with secret values replaced,
+// both in options such as --pin=1234 or --pin 1234 and in settings strings
+// such as --simple-connect="apn=internet,password=secret"
+func RedactArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if i > 0 && sensitiveOptions[args[i-1]] {
+			out[i] = redacted
+			continue
+		}
+		out[i] = redactArg(arg)
+	}
+	return out
+}
+
+func redactArg(arg string) string {
+	option, value, ok := strings.Cut(arg, "=")
+	if !ok || !strings.HasPrefix(option, "--") {
+		return arg
+	}
+	if sensitiveOptions[option] {
+		return option + "=" + redacted
+	}
+
+	// settings strings are passed with literal quotes
+	quote := ""
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		quote, value = `"`, value[1:len(value)-1]
+	}
+	return option + "=" + quote + redactSettings(value) + quote
+}
+
+// redactSettings replaces the value of the first sensitive key in a key=value
+// settings string and everything following it. Values are not escaped, so a
+// secret such as an SMS text may itself contain ",key=" and cannot be told
+// apart from the keys after it.
+func redactSettings(settings string) string {
+	for _, m := range settingsKeyRe.FindAllStringSubmatchIndex(settings, -1) {
+		if sensitiveKeys[settings[m[2]:m[3]]] {
+			return settings[:m[1]] + redacted
+		}
+	}
+	return settings
+}
+
+// logInvocation logs a finished mmcli invocation if the client has a logger
+func (c *Client) logInvocation(ctx context.Context, args []string, duration time.Duration, res *Result, err error) {
+	if c == nil || c.logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("command", "mmcli "+strings.Join(RedactArgs(args), " ")),
+		slog.Duration("duration", duration),
+	}
+
+	level := slog.LevelInfo
+	if res != nil {
+		attrs = append(attrs, slog.Int("exit_code", res.ExitCode))
+		if res.ExitCode != 0 {
+			level = slog.LevelWarn
+			if e := parseError(args, res); e.Message != "" {
+				attrs = append(attrs, slog.String("error", e.Error()))
+			}
+		}
+	}
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	c.logger.LogAttrs(ctx, level, "mmcli invocation", attrs...)
+}
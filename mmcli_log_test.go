@@ -0,0 +1,109 @@
+package mmcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactArgs(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{
+			arg:  `--simple-connect="apn=internet,user=scooter,password=s3cr3t,pin=1234"`,
+			want: `--simple-connect="apn=internet,user=scooter,password=REDACTED"`,
+		},
+		{
+			arg:  `--simple-connect="apn=internet,password=se,cr=et,ip-type=ipv4"`,
+			want: `--simple-connect="apn=internet,password=REDACTED"`,
+		},
+		{
+			arg:  `--messaging-create-sms="number=+4915112345678,text=unlock code 4711, valid=until noon,smsc=+491710760000"`,
+			want: `--messaging-create-sms="number=+4915112345678,text=REDACTED"`,
+		},
+		{
+			arg:  `--messaging-create-sms="number=+4915112345678,text=hi,apn=secret"`,
+			want: `--messaging-create-sms="number=+4915112345678,text=REDACTED"`,
+		},
+		{arg: "--pin=1234", want: "--pin=REDACTED"},
+		{arg: "--puk=12345678", want: "--puk=REDACTED"},
+		{arg: "--change-pin=0000", want: "--change-pin=REDACTED"},
+		{arg: "--location-set-supl-server=supl.google.com:7275", want: "--location-set-supl-server=supl.google.com:7275"},
+		{arg: "-m", want: "-m"},
+		{arg: "pin=1234", want: "pin=1234"},
+	}
+
+	for _, tt := range tests {
+		got := RedactArgs([]string{tt.arg})
+		if got[0] != tt.want {
+			t.Errorf("RedactArgs(%q) = %q, expected %q", tt.arg, got[0], tt.want)
+		}
+	}
+
+	got := strings.Join(RedactArgs([]string{"-i", "0", "--pin", "1234", "--change-pin", "0000"}), " ")
+	if got != "-i 0 --pin REDACTED --change-pin REDACTED" {
+		t.Errorf("Expected separate option values to be redacted, got %q", got)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		`-m 0 --simple-connect="apn=internet,password=s3cr3t"`: {},
+		`-i 0 --pin=1234`: {
+			Stderr:   []byte("error: couldn't send PIN code to the SIM: 'GDBus.Error:org.freedesktop.ModemManager1.Error.MobileEquipment.IncorrectPassword: Incorrect password'"),
+			ExitCode: 1,
+		},
+	}}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	client := NewClient(runner, WithLogger(logger))
+
+	if err := client.Connect(context.Background(), "0", ConnectSettings{APN: "internet", Password: "s3cr3t"}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	_, err := client.run(context.Background(), "-i", "0", "--pin=1234")
+	var mmErr *Error
+	if !errors.As(err, &mmErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	if strings.Contains(strings.Join(mmErr.Args, " "), "1234") {
+		t.Errorf("Expected the PIN to be redacted from the error, got %v", mmErr.Args)
+	}
+
+	if strings.Contains(buf.String(), "s3cr3t") || strings.Contains(buf.String(), "1234") {
+		t.Errorf("Secrets leaked into the log:\n%s", buf.String())
+	}
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 log entries, got %d", len(entries))
+	}
+
+	if entries[0]["level"] != "INFO" || entries[0]["exit_code"] != float64(0) ||
+		entries[0]["command"] != `mmcli -m 0 --simple-connect="apn=internet,password=REDACTED"` {
+		t.Errorf("Unexpected log entry %v", entries[0])
+	}
+	if _, ok := entries[0]["duration"]; !ok {
+		t.Errorf("Expected a duration, got %v", entries[0])
+	}
+
+	if entries[1]["level"] != "WARN" || entries[1]["exit_code"] != float64(1) ||
+		!strings.Contains(entries[1]["error"].(string), "IncorrectPassword") {
+		t.Errorf("Unexpected log entry %v", entries[1])
+	}
+}
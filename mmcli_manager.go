@@ -0,0 +1,122 @@
+package mmcli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevel is a ModemManager daemon logging level
+type LogLevel string
+
+// Logging levels understood by mmcli --set-logging
+const (
+	LogLevelError   LogLevel = "ERR"
+	LogLevelWarning LogLevel = "WARN"
+	LogLevelMessage LogLevel = "MSG"
+	LogLevelInfo    LogLevel = "INFO"
+	LogLevelDebug   LogLevel = "DEBUG"
+)
+
+// ParseLogLevel parses a logging level, e.g. "debug" or "DEBUG"
+func ParseLogLevel(s string) (LogLevel, error) {
+	switch level := LogLevel(strings.ToUpper(s)); level {
+	case LogLevelError, LogLevelWarning, LogLevelMessage, LogLevelInfo, LogLevelDebug:
+		return level, nil
+	}
+	return "", fmt.Errorf("unknown logging level %q", s)
+}
+
+// SetLogging sets the logging level of the ModemManager daemon
+func SetLogging(level LogLevel) error {
+	return SetLoggingContext(context.Background(), level)
+}
+
+// SetLoggingContext is like SetLogging but uses ctx to bound the mmcli invocation
+func SetLoggingContext(ctx context.Context, level LogLevel) error {
+	return DefaultClient.SetLogging(ctx, level)
+}
+
+// SetLogging sets the logging level of the ModemManager daemon
+func (c *Client) SetLogging(ctx context.Context, level LogLevel) error {
+	if _, err := ParseLogLevel(string(level)); err != nil {
+		return fmt.Errorf("failed to set logging level: %w", err)
+	}
+	if _, err := c.run(ctx, "--set-logging="+string(level)); err != nil {
+		return fmt.Errorf("failed to set logging level: %w", err)
+	}
+	return nil
+}
+
+// ScanModems asks ModemManager to look for new modems, e.g. after a device
+// was plugged in on systems without udev
+func ScanModems() error {
+	return ScanModemsContext(context.Background())
+}
+
+// ScanModemsContext is like ScanModems but uses ctx to bound the mmcli invocation
+func ScanModemsContext(ctx context.Context) error {
+	return DefaultClient.ScanModems(ctx)
+}
+
+// ScanModems asks ModemManager to look for new modems
+func (c *Client) ScanModems(ctx context.Context) error {
+	if _, err := c.run(ctx, "-S"); err != nil {
+		return fmt.Errorf("failed to scan for modems: %w", err)
+	}
+	return nil
+}
+
+// EnableDebugLogging switches the ModemManager daemon to debug logging and
+// sets the restore level once d has passed. See Client.EnableDebugLogging.
+func EnableDebugLogging(d time.Duration, restore LogLevel) (func() error, error) {
+	return EnableDebugLoggingContext(context.Background(), d, restore)
+}
+
+// EnableDebugLoggingContext is like EnableDebugLogging but uses ctx to bound
+// the mmcli invocation
+func EnableDebugLoggingContext(ctx context.Context, d time.Duration, restore LogLevel) (func() error, error) {
+	return DefaultClient.EnableDebugLogging(ctx, d, restore)
+}
+
+// restoreLoggingTimeout bounds restoring the log level after EnableDebugLogging
+const restoreLoggingTimeout = 30 * time.Second
+
+// EnableDebugLogging switches the ModemManager daemon to debug logging and
+// sets the restore level once d has passed, e.g. while diagnosing a device
+// remotely. mmcli cannot read the current level, so the level to restore has
+// to be given.
+//
+// The returned function restores the level right away and stops the timer;
+// calling it again has no effect. Cancelling ctx does not restore the level
+// early. Restoring gives up after 30 seconds. If the process exits before d
+// has passed, the daemon keeps logging at debug level.
+func (c *Client) EnableDebugLogging(ctx context.Context, d time.Duration, restore LogLevel) (func() error, error) {
+	if _, err := ParseLogLevel(string(restore)); err != nil {
+		return nil, fmt.Errorf("failed to enable debug logging: %w", err)
+	}
+	if err := c.SetLogging(ctx, LogLevelDebug); err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	var restoreErr error
+	restoreNow := func() error {
+		once.Do(func() {
+			// restoring must not depend on the caller's ctx, which may be
+			// long gone, but must not hang on a stuck mmcli or daemon either
+			restoreCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), restoreLoggingTimeout)
+			defer cancel()
+			restoreErr = c.SetLogging(restoreCtx, restore)
+		})
+		return restoreErr
+	}
+
+	timer := time.AfterFunc(d, func() { restoreNow() })
+	return func() error {
+		timer.Stop()
+		return restoreNow()
+	}, nil
+}
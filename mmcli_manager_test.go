@@ -0,0 +1,129 @@
+package mmcli
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	for input, expected := range map[string]LogLevel{"debug": LogLevelDebug, "ERR": LogLevelError, "Warn": LogLevelWarning} {
+		if level, err := ParseLogLevel(input); err != nil || level != expected {
+			t.Errorf("ParseLogLevel(%q) = %q, %v, expected %q", input, level, err, expected)
+		}
+	}
+	if _, err := ParseLogLevel("verbose"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestManagerOperations(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"--set-logging=INFO": {},
+		"-S":                 {},
+	}}
+	client := NewClient(runner)
+	ctx := context.Background()
+
+	if err := client.SetLogging(ctx, LogLevelInfo); err != nil {
+		t.Errorf("Failed to set logging level: %v", err)
+	}
+	if err := client.ScanModems(ctx); err != nil {
+		t.Errorf("Failed to scan modems: %v", err)
+	}
+	if err := client.SetLogging(ctx, "TRACE"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	if len(runner.calls) != 2 {
+		t.Errorf("Expected unknown levels not to reach mmcli, got %v", runner.calls)
+	}
+}
+
+// levelRunner records the logging levels set through mmcli
+type levelRunner struct {
+	mu     sync.Mutex
+	levels []string
+	set    chan struct{}
+}
+
+func (r *levelRunner) Run(ctx context.Context, args ...string) (*Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.levels = append(r.levels, strings.TrimPrefix(args[0], "--set-logging="))
+	r.set <- struct{}{}
+	return &Result{}, nil
+}
+
+func (r *levelRunner) Levels() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.levels, " ")
+}
+
+func TestEnableDebugLogging(t *testing.T) {
+	runner := &levelRunner{set: make(chan struct{}, 4)}
+	client := NewClient(runner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := client.EnableDebugLogging(ctx, 10*time.Millisecond, LogLevelWarning); err != nil {
+		t.Fatalf("Failed to enable debug logging: %v", err)
+	}
+	// the level is restored after the duration even though ctx ended
+	cancel()
+	<-runner.set
+	select {
+	case <-runner.set:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the level to be restored")
+	}
+	if levels := runner.Levels(); levels != "DEBUG WARN" {
+		t.Errorf("Expected DEBUG and then WARN, got %s", levels)
+	}
+
+	restore, err := client.EnableDebugLogging(context.Background(), time.Hour, LogLevelInfo)
+	if err != nil {
+		t.Fatalf("Failed to enable debug logging: %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Errorf("Failed to restore the level: %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Errorf("Failed to restore the level again: %v", err)
+	}
+	if levels := runner.Levels(); levels != "DEBUG WARN DEBUG INFO" {
+		t.Errorf("Expected the level to be restored once, got %s", levels)
+	}
+
+	if _, err := client.EnableDebugLogging(context.Background(), time.Minute, "TRACE"); err == nil {
+		t.Error("Expected an error for an unknown restore level")
+	}
+}
+
+func TestEnableDebugLoggingRestoreDeadline(t *testing.T) {
+	var restoreArgs []string
+	var restoreDeadline time.Time
+	runner := RunnerFunc(func(ctx context.Context, args ...string) (*Result, error) {
+		if args[0] != "--set-logging=DEBUG" {
+			restoreArgs = args
+			restoreDeadline, _ = ctx.Deadline()
+		}
+		return &Result{}, nil
+	})
+	client := NewClient(runner)
+
+	restore, err := client.EnableDebugLogging(context.Background(), time.Hour, LogLevelInfo)
+	if err != nil {
+		t.Fatalf("Failed to enable debug logging: %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("Failed to restore the level: %v", err)
+	}
+	if restoreDeadline.IsZero() || time.Until(restoreDeadline) > restoreLoggingTimeout {
+		t.Errorf("Expected restoring to be bounded by %v, got deadline %v", restoreLoggingTimeout, restoreDeadline)
+	}
+	if len(restoreArgs) != 2 || !strings.HasPrefix(restoreArgs[1], "--timeout=") {
+		t.Errorf("Expected mmcli to be given the timeout, got %v", restoreArgs)
+	}
+}
@@ -0,0 +1,200 @@
+package mmcli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Mode is a set of network modes. The bits match MMModemMode.
+type Mode uint32
+
+// Modes
+const (
+	ModeNone Mode = 0
+	ModeCS   Mode = 1 << 0 // circuit switched, voice and SMS without data
+	Mode2G   Mode = 1 << 1
+	Mode3G   Mode = 1 << 2
+	Mode4G   Mode = 1 << 3
+	Mode5G   Mode = 1 << 4
+	ModeAny  Mode = 0xFFFFFFFF
+)
+
+var modeNames = []struct {
+	mode Mode
+	name string
+}{
+	{ModeCS, "cs"},
+	{Mode2G, "2g"},
+	{Mode3G, "3g"},
+	{Mode4G, "4g"},
+	{Mode5G, "5g"},
+}
+
+// ParseModes parses modes as printed by mmcli, e.g. "2g, 3g, 4g". The "|"
+// separated form mmcli accepts as input, e.g. "3g|4g", is accepted as well.
+func ParseModes(s string) (Mode, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "none", "":
+		return ModeNone, nil
+	case "any":
+		return ModeAny, nil
+	}
+
+	var modes Mode
+	for _, name := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '|' }) {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, m := range modeNames {
+			if m.name == name {
+				modes |= m.mode
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ModeNone, fmt.Errorf("unknown mode %q", name)
+		}
+	}
+	return modes, nil
+}
+
+// Has reports whether all modes in mode are in the set
+func (m Mode) Has(mode Mode) bool {
+	return mode != 0 && m&mode == mode
+}
+
+// Strings returns the modes in the set, e.g. []string{"3g", "4g"}
+func (m Mode) Strings() []string {
+	var names []string
+	for _, mn := range modeNames {
+		if m&mn.mode != 0 {
+			names = append(names, mn.name)
+		}
+	}
+	return names
+}
+
+// String returns the set as printed by mmcli, e.g. "2g, 3g, 4g"
+func (m Mode) String() string {
+	switch m {
+	case ModeNone:
+		return "none"
+	case ModeAny:
+		return "any"
+	}
+	return strings.Join(m.Strings(), ", ")
+}
+
+// argument returns the set in the form mmcli accepts as input, e.g. "3g|4g"
+func (m Mode) argument() string {
+	if m == ModeAny {
+		return "any"
+	}
+	return strings.Join(m.Strings(), "|")
+}
+
+// ModeCombination is a pair of allowed modes and the preferred mode among them
+type ModeCombination struct {
+	Allowed   Mode
+	Preferred Mode
+}
+
+// ParseModeCombination parses a mode combination as printed by mmcli, e.g.
+// "allowed: 2g, 3g, 4g; preferred: 4g"
+func ParseModeCombination(s string) (ModeCombination, error) {
+	var mc ModeCombination
+	var haveAllowed bool
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return ModeCombination{}, fmt.Errorf("invalid mode combination %q", s)
+		}
+
+		modes, err := ParseModes(value)
+		if err != nil {
+			return ModeCombination{}, fmt.Errorf("invalid mode combination %q: %w", s, err)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "allowed":
+			mc.Allowed = modes
+			haveAllowed = true
+		case "preferred":
+			mc.Preferred = modes
+		default:
+			return ModeCombination{}, fmt.Errorf("invalid mode combination %q", s)
+		}
+	}
+	if !haveAllowed {
+		return ModeCombination{}, fmt.Errorf("invalid mode combination %q", s)
+	}
+	return mc, nil
+}
+
+// String returns the combination as printed by mmcli
+func (mc ModeCombination) String() string {
+	return fmt.Sprintf("allowed: %s; preferred: %s", mc.Allowed, mc.Preferred)
+}
+
+// Allows reports whether all modes in mode are allowed
+func (mc ModeCombination) Allows(mode Mode) bool {
+	return mc.Allowed.Has(mode)
+}
+
+// CurrentModes returns the modes the modem is currently allowed to use
+func (mm *ModemManager) CurrentModes() (ModeCombination, error) {
+	return ParseModeCombination(mm.Modem.Generic.CurrentModes)
+}
+
+// SupportedModes returns the mode combinations the modem supports.
+// Combinations this package cannot parse are ignored.
+func (mm *ModemManager) SupportedModes() []ModeCombination {
+	var supported []ModeCombination
+	for _, s := range mm.Modem.Generic.SupportedModes {
+		if mc, err := ParseModeCombination(s); err == nil {
+			supported = append(supported, mc)
+		}
+	}
+	return supported
+}
+
+// SupportsModes reports whether the modem supports the given combination
+func (mm *ModemManager) SupportsModes(mc ModeCombination) bool {
+	for _, supported := range mm.SupportedModes() {
+		if supported == mc {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCurrentModes sets the allowed and preferred modes of a modem
+func SetCurrentModes(modemID string, modes ModeCombination) error {
+	return SetCurrentModesContext(context.Background(), modemID, modes)
+}
+
+// SetCurrentModesContext is like SetCurrentModes but uses ctx to bound the mmcli invocation
+func SetCurrentModesContext(ctx context.Context, modemID string, modes ModeCombination) error {
+	return DefaultClient.SetCurrentModes(ctx, modemID, modes)
+}
+
+// SetCurrentModes sets the allowed and preferred modes of a modem
+func (c *Client) SetCurrentModes(ctx context.Context, modemID string, modes ModeCombination) error {
+	if modes.Allowed == ModeNone {
+		return fmt.Errorf("no allowed modes given")
+	}
+
+	args := []string{"-m", modemID, "--set-allowed-modes=" + modes.Allowed.argument()}
+	if modes.Preferred != ModeNone {
+		args = append(args, "--set-preferred-mode="+modes.Preferred.argument())
+	}
+
+	_, err := c.run(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to set current modes: %w", err)
+	}
+
+	return nil
+}
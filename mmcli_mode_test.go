@@ -0,0 +1,124 @@
+package mmcli
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseModes(t *testing.T) {
+	tests := map[string]Mode{
+		"2g, 3g, 4g": Mode2G | Mode3G | Mode4G,
+		"4g":         Mode4G,
+		"3g|4g|5g":   Mode3G | Mode4G | Mode5G,
+		"cs, 2g":     ModeCS | Mode2G,
+		"none":       ModeNone,
+		"any":        ModeAny,
+	}
+
+	for input, want := range tests {
+		got, err := ParseModes(input)
+		if err != nil {
+			t.Errorf("ParseModes(%q): %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseModes(%q) = %s, expected %s", input, got, want)
+		}
+	}
+
+	if _, err := ParseModes("2g, 6g"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestParseModeCombination(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ModeCombination
+	}{
+		{"allowed: 2g, 3g, 4g; preferred: 4g", ModeCombination{Mode2G | Mode3G | Mode4G, Mode4G}},
+		{"allowed: 4g; preferred: none", ModeCombination{Mode4G, ModeNone}},
+		{"allowed: 4g, 5g; preferred: 5g", ModeCombination{Mode4G | Mode5G, Mode5G}},
+		{"allowed: any; preferred: none", ModeCombination{ModeAny, ModeNone}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseModeCombination(tt.input)
+		if err != nil {
+			t.Errorf("ParseModeCombination(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseModeCombination(%q) = %s, expected %s", tt.input, got, tt.want)
+		}
+		if got.String() != tt.input {
+			t.Errorf("String() = %q, expected %q", got.String(), tt.input)
+		}
+	}
+
+	for _, input := range []string{"", "--", "preferred: 4g", "allowed: 4g; favourite: 4g", "allowed: 7g; preferred: none"} {
+		if _, err := ParseModeCombination(input); err == nil {
+			t.Errorf("ParseModeCombination(%q): expected an error", input)
+		}
+	}
+}
+
+func TestModemManagerModes(t *testing.T) {
+	var mm ModemManager
+	mm.Modem.Generic.CurrentModes = "allowed: 2g, 3g, 4g; preferred: 4g"
+	mm.Modem.Generic.SupportedModes = []string{
+		"allowed: 2g; preferred: none",
+		"allowed: 4g; preferred: none",
+		"allowed: 2g, 3g, 4g; preferred: 4g",
+	}
+
+	current, err := mm.CurrentModes()
+	if err != nil {
+		t.Fatalf("Failed to parse current modes: %v", err)
+	}
+	if !current.Allows(Mode4G) || current.Allows(Mode5G) || current.Preferred != Mode4G {
+		t.Errorf("Unexpected current modes %s", current)
+	}
+
+	if len(mm.SupportedModes()) != 3 {
+		t.Errorf("Expected 3 supported mode combinations, got %v", mm.SupportedModes())
+	}
+	if !mm.SupportsModes(ModeCombination{Allowed: Mode4G}) {
+		t.Error("Expected 4G only to be supported")
+	}
+	if mm.SupportsModes(ModeCombination{Allowed: Mode4G | Mode5G, Preferred: Mode5G}) {
+		t.Error("Expected 5G not to be supported")
+	}
+}
+
+func TestSetCurrentModes(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-m 0 --set-allowed-modes=3g|4g --set-preferred-mode=4g": {},
+		"-m 0 --set-allowed-modes=4g":                            {},
+	}}
+	client := NewClient(runner)
+	ctx := context.Background()
+
+	if err := client.SetCurrentModes(ctx, "0", ModeCombination{Allowed: Mode3G | Mode4G, Preferred: Mode4G}); err != nil {
+		t.Errorf("Failed to set modes: %v", err)
+	}
+
+	// a combination read from the modem can be passed back unchanged
+	mc, _ := ParseModeCombination("allowed: 4g; preferred: none")
+	if err := client.SetCurrentModes(ctx, "0", mc); err != nil {
+		t.Errorf("Failed to set modes: %v", err)
+	}
+
+	if err := client.SetCurrentModes(ctx, "0", ModeCombination{}); err == nil {
+		t.Error("Expected an error without allowed modes")
+	}
+
+	expected := [][]string{
+		{"-m", "0", "--set-allowed-modes=3g|4g", "--set-preferred-mode=4g"},
+		{"-m", "0", "--set-allowed-modes=4g"},
+	}
+	if !reflect.DeepEqual(runner.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, runner.calls)
+	}
+}
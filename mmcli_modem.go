@@ -0,0 +1,310 @@
+package mmcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ModemPathPrefix is the D-Bus object path prefix shared by all modems
+const ModemPathPrefix = "/org/freedesktop/ModemManager1/Modem/"
+
+// ModemIndexFromPath returns the modem index encoded in a modem D-Bus path
+func ModemIndexFromPath(path string) (int, error) {
+	if !strings.HasPrefix(path, ModemPathPrefix) {
+		return 0, fmt.Errorf("invalid modem path format: %s", path)
+	}
+	index, err := strconv.Atoi(strings.TrimPrefix(path, ModemPathPrefix))
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid modem path format: %s", path)
+	}
+	return index, nil
+}
+
+// ModemHandle refers to a single modem through a Backend.
+//
+// A handle is created from any selector mmcli -m accepts: a D-Bus path, an
+// index, a device UID or "any". Once the modem has been looked up, the handle
+// remembers the modem's device UID, so if ModemManager re-probes the modem
+// under a new index the handle re-resolves itself transparently.
+type ModemHandle struct {
+	backend  Backend
+	selector string
+
+	mu     sync.Mutex
+	path   string
+	device string
+}
+
+// NewModemHandle returns a handle for the modem matching the given selector
+// (D-Bus path, index, device UID or "any") on the given backend
+func NewModemHandle(backend Backend, selector string) *ModemHandle {
+	return &ModemHandle{backend: backend, selector: selector}
+}
+
+// Modem returns a handle for the modem matching the given selector
+// (D-Bus path, index, device UID or "any")
+func (c *Client) Modem(selector string) *ModemHandle {
+	return NewModemHandle(c, selector)
+}
+
+// ModemByIndex returns a handle for the modem with the given index
+func (c *Client) ModemByIndex(index int) *ModemHandle {
+	return c.Modem(strconv.Itoa(index))
+}
+
+// AnyModem returns a handle for whichever modem ModemManager reports first
+func (c *Client) AnyModem() *ModemHandle {
+	return c.Modem("any")
+}
+
+// ModemByEquipmentID returns a handle for the modem with the given equipment
+// identifier (usually the IMEI). Modems that disappear during the lookup are
+// skipped; other errors are only returned if no modem matched.
+func (c *Client) ModemByEquipmentID(ctx context.Context, equipmentID string) (*ModemHandle, error) {
+	paths, err := c.ListModems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var detailsErr error
+	for _, path := range paths {
+		m := c.Modem(path)
+		mm, err := m.Details(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			if !errors.Is(err, ErrModemNotFound) && detailsErr == nil {
+				detailsErr = err
+			}
+			continue
+		}
+		if mm.Modem.Generic.EquipmentIdentifier == equipmentID {
+			return m, nil
+		}
+	}
+
+	if detailsErr != nil {
+		return nil, detailsErr
+	}
+	return nil, fmt.Errorf("no modem with equipment identifier %s: %w", equipmentID, ErrModemNotFound)
+}
+
+// Selector returns the selector the handle was created with
+func (m *ModemHandle) Selector() string {
+	return m.selector
+}
+
+// Path returns the modem's D-Bus path, or an empty string if the handle has not been resolved yet
+func (m *ModemHandle) Path() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.path
+}
+
+// ID returns the argument currently passed to mmcli -m for this modem
+func (m *ModemHandle) ID() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.path != "" {
+		return m.path
+	}
+	return m.selector
+}
+
+// Resolve looks up the modem and binds the handle to its current D-Bus path
+func (m *ModemHandle) Resolve(ctx context.Context) error {
+	m.mu.Lock()
+	selector := m.selector
+	if m.device != "" {
+		selector = m.device
+	}
+	m.mu.Unlock()
+
+	mm, err := m.backend.GetModemDetails(ctx, selector)
+	if err != nil {
+		return err
+	}
+	m.update(mm)
+	return nil
+}
+
+// update records the identity of the modem from its details
+func (m *ModemHandle) update(mm *ModemManager) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mm.Modem.DBusPath != "" {
+		m.path = mm.Modem.DBusPath
+	}
+	if mm.Modem.Generic.Device != "" {
+		m.device = mm.Modem.Generic.Device
+	}
+}
+
+// do runs fn against the modem, re-resolving the handle once if the modem
+// disappeared from its previous path
+func (m *ModemHandle) do(ctx context.Context, fn func(id string) error) error {
+	err := fn(m.ID())
+	if !errors.Is(err, ErrModemNotFound) {
+		return err
+	}
+
+	m.mu.Lock()
+	canResolve := m.device != ""
+	m.mu.Unlock()
+	if !canResolve {
+		return err
+	}
+
+	if rerr := m.Resolve(ctx); rerr != nil {
+		return err
+	}
+	return fn(m.ID())
+}
+
+// Details returns the modem details
+func (m *ModemHandle) Details(ctx context.Context) (*ModemManager, error) {
+	var mm *ModemManager
+	err := m.do(ctx, func(id string) (err error) {
+		mm, err = m.backend.GetModemDetails(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.update(mm)
+	return mm, nil
+}
+
+// Reset resets the modem
+func (m *ModemHandle) Reset(ctx context.Context) error {
+	return m.do(ctx, func(id string) error {
+		_, err := m.backend.ResetModem(ctx, id)
+		return err
+	})
+}
+
+// SIM returns information about the SIM card in the modem
+func (m *ModemHandle) SIM(ctx context.Context) (*SIMInfo, error) {
+	mm, err := m.Details(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if mm.Modem.Generic.SIM == "" || mm.Modem.Generic.SIM == "--" {
+		return nil, fmt.Errorf("modem has no SIM: %w", ErrNotFound)
+	}
+	return m.backend.GetSIMInfo(ctx, mm.Modem.Generic.SIM)
+}
+
+// Connect establishes a connection with the specified settings
+func (m *ModemHandle) Connect(ctx context.Context, settings ConnectSettings) error {
+	return m.do(ctx, func(id string) error {
+		return m.backend.Connect(ctx, id, settings)
+	})
+}
+
+// Disconnect disconnects all connected bearers
+func (m *ModemHandle) Disconnect(ctx context.Context) error {
+	return m.do(ctx, func(id string) error {
+		return m.backend.Disconnect(ctx, id)
+	})
+}
+
+// LocationStatus returns the current status of location gathering
+func (m *ModemHandle) LocationStatus(ctx context.Context) (*LocationStatus, error) {
+	var status *LocationStatus
+	err := m.do(ctx, func(id string) (err error) {
+		status, err = m.backend.GetLocationStatus(ctx, id)
+		return err
+	})
+	return status, err
+}
+
+// Location returns the current location information
+func (m *ModemHandle) Location(ctx context.Context) (*LocationInfo, error) {
+	var location *LocationInfo
+	err := m.do(ctx, func(id string) (err error) {
+		location, err = m.backend.GetLocation(ctx, id)
+		return err
+	})
+	return location, err
+}
+
+// EnableLocationGathering enables a specific location gathering method
+func (m *ModemHandle) EnableLocationGathering(ctx context.Context, method string) error {
+	return m.do(ctx, func(id string) error {
+		return m.backend.EnableLocationGathering(ctx, id, method)
+	})
+}
+
+// DisableLocationGathering disables a specific location gathering method
+func (m *ModemHandle) DisableLocationGathering(ctx context.Context, method string) error {
+	return m.do(ctx, func(id string) error {
+		return m.backend.DisableLocationGathering(ctx, id, method)
+	})
+}
+
+// MessagingStatus returns the status of messaging support
+func (m *ModemHandle) MessagingStatus(ctx context.Context) (*MessagingStatus, error) {
+	var status *MessagingStatus
+	err := m.do(ctx, func(id string) (err error) {
+		status, err = m.backend.GetMessagingStatus(ctx, id)
+		return err
+	})
+	return status, err
+}
+
+// SMS returns the list of SMS messages stored on the modem
+func (m *ModemHandle) SMS(ctx context.Context) ([]string, error) {
+	var list []string
+	err := m.do(ctx, func(id string) (err error) {
+		list, err = m.backend.ListSMS(ctx, id)
+		return err
+	})
+	return list, err
+}
+
+// CreateSMS creates a new SMS message and returns its ID
+func (m *ModemHandle) CreateSMS(ctx context.Context, settings SMSCreateSettings) (string, error) {
+	var smsID string
+	err := m.do(ctx, func(id string) (err error) {
+		smsID, err = m.backend.CreateSMS(ctx, id, settings)
+		return err
+	})
+	return smsID, err
+}
+
+// SendSMS creates and sends an SMS message in one step
+func (m *ModemHandle) SendSMS(ctx context.Context, number string, text string) error {
+	smsID, err := m.CreateSMS(ctx, SMSCreateSettings{Number: number, Text: text})
+	if err != nil {
+		return err
+	}
+	return m.backend.SendSMS(ctx, smsID)
+}
+
+// DeleteSMS deletes an SMS message
+func (m *ModemHandle) DeleteSMS(ctx context.Context, smsID string) error {
+	return m.do(ctx, func(id string) error {
+		return m.backend.DeleteSMS(ctx, id, smsID)
+	})
+}
+
+// NetworkTime returns the current network time
+func (m *ModemHandle) NetworkTime(ctx context.Context) (time.Time, error) {
+	var t time.Time
+	err := m.do(ctx, func(id string) (err error) {
+		info, err := m.backend.GetNetworkTime(ctx, id)
+		if err != nil {
+			return err
+		}
+		t, err = info.Time()
+		return err
+	})
+	return t, err
+}
@@ -0,0 +1,119 @@
+package mmcli
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestModemIndexFromPath(t *testing.T) {
+	index, err := ModemIndexFromPath("/org/freedesktop/ModemManager1/Modem/12")
+	if err != nil {
+		t.Fatalf("Failed to parse modem path: %v", err)
+	}
+	if index != 12 {
+		t.Errorf("Expected index 12, got %d", index)
+	}
+
+	for _, path := range []string{"", "/org/freedesktop/ModemManager1/SIM/0", "/org/freedesktop/ModemManager1/Modem/x"} {
+		if _, err := ModemIndexFromPath(path); err == nil {
+			t.Errorf("Expected error for path %q", path)
+		}
+	}
+}
+
+func TestModemHandleReresolves(t *testing.T) {
+	const device = "/sys/devices/platform/soc/usb1/1-1"
+	modem0 := []byte(`{"modem": {"dbus-path": "/org/freedesktop/ModemManager1/Modem/0", "generic": {"device": "` + device + `", "state": "registered"}}}`)
+	modem1 := []byte(`{"modem": {"dbus-path": "/org/freedesktop/ModemManager1/Modem/1", "generic": {"device": "` + device + `", "state": "connected"}}}`)
+
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-m 0 -J": {Stdout: modem0},
+		"-m /org/freedesktop/ModemManager1/Modem/0 -J": {Stderr: []byte("error: couldn't find modem\n"), ExitCode: 1},
+		"-m " + device + " -J":                         {Stdout: modem1},
+		"-m /org/freedesktop/ModemManager1/Modem/1 -J": {Stdout: modem1},
+	}}
+	client := NewClient(runner)
+	ctx := context.Background()
+
+	m := client.ModemByIndex(0)
+	if m.ID() != "0" {
+		t.Errorf("Expected unresolved handle to use selector 0, got %s", m.ID())
+	}
+
+	if _, err := m.Details(ctx); err != nil {
+		t.Fatalf("Failed to get modem details: %v", err)
+	}
+	if m.Path() != "/org/freedesktop/ModemManager1/Modem/0" {
+		t.Errorf("Expected handle bound to Modem/0, got %s", m.Path())
+	}
+
+	// The modem was re-probed and now lives at Modem/1
+	mm, err := m.Details(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get modem details after re-probe: %v", err)
+	}
+	if !mm.IsConnected() {
+		t.Error("Expected details of the re-probed modem")
+	}
+	if m.Path() != "/org/freedesktop/ModemManager1/Modem/1" {
+		t.Errorf("Expected handle bound to Modem/1, got %s", m.Path())
+	}
+}
+
+func TestModemHandleNotFound(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-m any -J": {Stderr: []byte("error: couldn't find modem\n"), ExitCode: 1},
+	}}
+	client := NewClient(runner)
+
+	if _, err := client.AnyModem().Details(context.Background()); !errors.Is(err, ErrModemNotFound) {
+		t.Errorf("Expected ErrModemNotFound, got %v", err)
+	}
+	if len(runner.calls) != 1 {
+		t.Errorf("Expected no re-resolution for an unresolved handle, got %v", runner.calls)
+	}
+}
+
+func TestModemByEquipmentID(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-J -L": {Stdout: []byte(`{"modem-list": ["/org/freedesktop/ModemManager1/Modem/0", "/org/freedesktop/ModemManager1/Modem/1"]}`)},
+		"-m /org/freedesktop/ModemManager1/Modem/0 -J": {Stdout: []byte(`{"modem": {"dbus-path": "/org/freedesktop/ModemManager1/Modem/0", "generic": {"equipment-identifier": "111"}}}`)},
+		"-m /org/freedesktop/ModemManager1/Modem/1 -J": {Stdout: []byte(`{"modem": {"dbus-path": "/org/freedesktop/ModemManager1/Modem/1", "generic": {"equipment-identifier": "222"}}}`)},
+	}}
+	client := NewClient(runner)
+
+	m, err := client.ModemByEquipmentID(context.Background(), "222")
+	if err != nil {
+		t.Fatalf("Failed to find modem: %v", err)
+	}
+	if m.Path() != "/org/freedesktop/ModemManager1/Modem/1" {
+		t.Errorf("Expected Modem/1, got %s", m.Path())
+	}
+
+	if _, err := client.ModemByEquipmentID(context.Background(), "333"); !errors.Is(err, ErrModemNotFound) {
+		t.Errorf("Expected ErrModemNotFound, got %v", err)
+	}
+}
+
+func TestModemByEquipmentIDSkipsVanishedModems(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-J -L": {Stdout: []byte(`{"modem-list": ["/org/freedesktop/ModemManager1/Modem/0", "/org/freedesktop/ModemManager1/Modem/1", "/org/freedesktop/ModemManager1/Modem/2"]}`)},
+		"-m /org/freedesktop/ModemManager1/Modem/0 -J": {Stderr: []byte("error: couldn't find modem\n"), ExitCode: 1},
+		"-m /org/freedesktop/ModemManager1/Modem/1 -J": {Stderr: []byte("error: couldn't get modem: Unauthorized\n"), ExitCode: 1},
+		"-m /org/freedesktop/ModemManager1/Modem/2 -J": {Stdout: []byte(`{"modem": {"dbus-path": "/org/freedesktop/ModemManager1/Modem/2", "generic": {"equipment-identifier": "222"}}}`)},
+	}}
+	client := NewClient(runner)
+
+	m, err := client.ModemByEquipmentID(context.Background(), "222")
+	if err != nil {
+		t.Fatalf("Failed to find modem: %v", err)
+	}
+	if m.Path() != "/org/freedesktop/ModemManager1/Modem/2" {
+		t.Errorf("Expected Modem/2, got %s", m.Path())
+	}
+
+	if _, err := client.ModemByEquipmentID(context.Background(), "333"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected the error of Modem/1 if no modem matched, got %v", err)
+	}
+}
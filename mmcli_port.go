@@ -0,0 +1,140 @@
+package mmcli
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PortType is the type of a modem port. The values match MMModemPortType.
+type PortType uint32
+
+// Port types
+const (
+	PortTypeUnknown PortType = 1
+	PortTypeNet     PortType = 2
+	PortTypeAT      PortType = 3
+	PortTypeQCDM    PortType = 4
+	PortTypeGPS     PortType = 5
+	PortTypeQMI     PortType = 6
+	PortTypeMBIM    PortType = 7
+	PortTypeAudio   PortType = 8
+	PortTypeIgnored PortType = 9
+	PortTypeXMMRPC  PortType = 10
+)
+
+var portTypeNames = map[PortType]string{
+	PortTypeUnknown: "unknown",
+	PortTypeNet:     "net",
+	PortTypeAT:      "at",
+	PortTypeQCDM:    "qcdm",
+	PortTypeGPS:     "gps",
+	PortTypeQMI:     "qmi",
+	PortTypeMBIM:    "mbim",
+	PortTypeAudio:   "audio",
+	PortTypeIgnored: "ignored",
+	PortTypeXMMRPC:  "xmmrpc",
+}
+
+// ParsePortType parses a port type as printed by mmcli, e.g. "qmi"
+func ParsePortType(s string) (PortType, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for t, name := range portTypeNames {
+		if name == s {
+			return t, nil
+		}
+	}
+	return PortTypeUnknown, fmt.Errorf("unknown port type %q", s)
+}
+
+// String returns the port type as printed by mmcli
+func (t PortType) String() string {
+	if name, ok := portTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Port is a kernel device exposed by a modem, e.g. ttyUSB2 or wwan0
+type Port struct {
+	Name string
+	Type PortType
+}
+
+// ParsePort parses a port as printed by mmcli, e.g. "cdc-wdm0 (qmi)". Ports
+// of types this package does not know get PortTypeUnknown.
+func ParsePort(s string) (Port, error) {
+	name, rest, ok := strings.Cut(strings.TrimSpace(s), " ")
+	rest = strings.TrimSpace(rest)
+	if !ok || name == "" || !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return Port{}, fmt.Errorf("invalid port %q", s)
+	}
+	portType, _ := ParsePortType(rest[1 : len(rest)-1])
+	return Port{Name: name, Type: portType}, nil
+}
+
+// String returns the port as printed by mmcli
+func (p Port) String() string {
+	return fmt.Sprintf("%s (%s)", p.Name, p.Type)
+}
+
+// SysfsPath returns the port's directory in /sys/class/net. It returns an
+// error for ports that are not network interfaces or are not present.
+func (p Port) SysfsPath() (string, error) {
+	return p.SysfsPathIn("/sys/class/net")
+}
+
+// SysfsPathIn is like SysfsPath but looks for the network interface in root,
+// e.g. in a sysfs mounted elsewhere
+func (p Port) SysfsPathIn(root string) (string, error) {
+	if p.Type != PortTypeNet {
+		return "", fmt.Errorf("port %s is not a network interface", p.Name)
+	}
+	path := filepath.Join(root, p.Name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("failed to find network interface %s: %w", p.Name, err)
+	}
+	return path, nil
+}
+
+// Interface returns the network interface of a net port
+func (p Port) Interface() (*net.Interface, error) {
+	if p.Type != PortTypeNet {
+		return nil, fmt.Errorf("port %s is not a network interface", p.Name)
+	}
+	iface, err := net.InterfaceByName(p.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find network interface %s: %w", p.Name, err)
+	}
+	return iface, nil
+}
+
+// Ports returns all ports of the modem. Entries mmcli printed in an
+// unexpected format are skipped.
+func (mm *ModemManager) Ports() []Port {
+	var ports []Port
+	for _, s := range mm.Modem.Generic.Ports {
+		if p, err := ParsePort(s); err == nil {
+			ports = append(ports, p)
+		}
+	}
+	return ports
+}
+
+// PortsByType returns all ports of the given type, e.g. every AT port
+func (mm *ModemManager) PortsByType(portType PortType) []Port {
+	var ports []Port
+	for _, p := range mm.Ports() {
+		if p.Type == portType {
+			ports = append(ports, p)
+		}
+	}
+	return ports
+}
+
+// NetPorts returns the modem's network interface ports
+func (mm *ModemManager) NetPorts() []Port {
+	return mm.PortsByType(PortTypeNet)
+}
@@ -0,0 +1,111 @@
+package mmcli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testPortsModem() *ModemManager {
+	var mm ModemManager
+	mm.Modem.Generic.Ports = []string{
+		"cdc-wdm0 (qmi)",
+		"ttyUSB0 (ignored)",
+		"ttyUSB1 (gps)",
+		"ttyUSB2 (at)",
+		"ttyUSB3 (at)",
+		"wwan0 (net)",
+		"wwan1 (net)",
+		"ttyUSB4 (fancy)",
+		"garbage",
+	}
+	return &mm
+}
+
+func TestParsePort(t *testing.T) {
+	tests := map[string]Port{
+		"cdc-wdm0 (qmi)":   {Name: "cdc-wdm0", Type: PortTypeQMI},
+		"wwan0 (net)":      {Name: "wwan0", Type: PortTypeNet},
+		"ttyUSB2 (at)":     {Name: "ttyUSB2", Type: PortTypeAT},
+		"ttyACM0 (mbim)":   {Name: "ttyACM0", Type: PortTypeMBIM},
+		"ttyUSB9 (future)": {Name: "ttyUSB9", Type: PortTypeUnknown},
+	}
+
+	for input, want := range tests {
+		got, err := ParsePort(input)
+		if err != nil {
+			t.Errorf("ParsePort(%q): %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParsePort(%q) = %+v, expected %+v", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"", "wwan0", "wwan0 net"} {
+		if _, err := ParsePort(input); err == nil {
+			t.Errorf("ParsePort(%q): expected an error", input)
+		}
+	}
+
+	if s := (Port{Name: "wwan0", Type: PortTypeNet}).String(); s != "wwan0 (net)" {
+		t.Errorf("Expected wwan0 (net), got %s", s)
+	}
+}
+
+func TestModemManagerPorts(t *testing.T) {
+	mm := testPortsModem()
+
+	if ports := mm.Ports(); len(ports) != 8 {
+		t.Errorf("Expected 8 ports, got %v", ports)
+	}
+
+	at := mm.PortsByType(PortTypeAT)
+	expected := []Port{{"ttyUSB2", PortTypeAT}, {"ttyUSB3", PortTypeAT}}
+	if !reflect.DeepEqual(at, expected) {
+		t.Errorf("Expected AT ports %v, got %v", expected, at)
+	}
+
+	if net := mm.NetPorts(); len(net) != 2 || net[1].Name != "wwan1" {
+		t.Errorf("Unexpected net ports %v", net)
+	}
+	if ports := mm.PortsByType(PortTypeAudio); ports != nil {
+		t.Errorf("Expected no audio ports, got %v", ports)
+	}
+
+	if port := mm.GetPortByType("at"); port != "ttyUSB2" {
+		t.Errorf("Expected first AT port ttyUSB2, got %s", port)
+	}
+	if port := mm.GetPortByType("FANCY"); port != "ttyUSB4" {
+		t.Errorf("Expected a port of an unknown type to be matched as printed, got %s", port)
+	}
+	if port := mm.GetPortByType("bogus"); port != "" {
+		t.Errorf("Expected no port, got %s", port)
+	}
+}
+
+func TestPortSysfsPath(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "wwan0"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path, err := Port{Name: "wwan0", Type: PortTypeNet}.SysfsPathIn(root)
+	if err != nil {
+		t.Fatalf("Failed to get sysfs path: %v", err)
+	}
+	if path != filepath.Join(root, "wwan0") {
+		t.Errorf("Unexpected sysfs path %s", path)
+	}
+
+	if _, err := (Port{Name: "wwan1", Type: PortTypeNet}).SysfsPathIn(root); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist for a missing interface, got %v", err)
+	}
+	if _, err := (Port{Name: "ttyUSB2", Type: PortTypeAT}).SysfsPath(); err == nil {
+		t.Error("Expected an error for an AT port")
+	}
+	if _, err := (Port{Name: "ttyUSB2", Type: PortTypeAT}).Interface(); err == nil {
+		t.Error("Expected an error for an AT port")
+	}
+}
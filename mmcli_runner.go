@@ -0,0 +1,75 @@
+package mmcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"time"
+)
+
+// Result holds the outcome of a single mmcli invocation
+type Result struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+}
+
+// Runner executes mmcli with the given arguments.
+//
+// A non-zero exit status is reported through Result.ExitCode; the returned
+// error is reserved for failures to run the command at all, including the
+// context being cancelled before mmcli finished.
+type Runner interface {
+	Run(ctx context.Context, args ...string) (*Result, error)
+}
+
+// RunnerFunc adapts an ordinary function to the Runner interface
+type RunnerFunc func(ctx context.Context, args ...string) (*Result, error)
+
+// Run calls f(ctx, args...)
+func (f RunnerFunc) Run(ctx context.Context, args ...string) (*Result, error) {
+	return f(ctx, args...)
+}
+
+// waitDelay bounds how long ExecRunner waits for output pipes after mmcli was killed
+const waitDelay = 2 * time.Second
+
+// ExecRunner runs the mmcli binary as a child process
+type ExecRunner struct {
+	// Path is the mmcli executable to run. If empty, "mmcli" is looked up in PATH.
+	Path string
+}
+
+// Run executes mmcli and collects its output. The child process is killed
+// when ctx is cancelled, in which case the context error is returned.
+func (r ExecRunner) Run(ctx context.Context, args ...string) (*Result, error) {
+	path := r.Path
+	if path == "" {
+		path = "mmcli"
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+
+	err := cmd.Run()
+	res := &Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+
+	if ctx.Err() != nil {
+		return res, ctx.Err()
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		res.ExitCode = exitErr.ExitCode()
+		return res, nil
+	}
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
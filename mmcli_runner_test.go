@@ -0,0 +1,164 @@
+package mmcli
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedRunner answers mmcli invocations from a fixed table keyed by the joined arguments
+type scriptedRunner struct {
+	responses map[string]*Result
+	calls     [][]string
+}
+
+func (r *scriptedRunner) Run(ctx context.Context, args ...string) (*Result, error) {
+	r.calls = append(r.calls, args)
+	if res, ok := r.responses[strings.Join(args, " ")]; ok {
+		return res, nil
+	}
+	return &Result{Stderr: []byte("error: unexpected invocation"), ExitCode: 1}, nil
+}
+
+func TestClientUsesRunner(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-J -L":   {Stdout: []byte(`{"modem-list": ["/org/freedesktop/ModemManager1/Modem/4"]}`)},
+		"-m 4 -J": {Stdout: []byte(`{"modem": {"generic": {"state": "connected"}}}`)},
+	}}
+	client := NewClient(runner)
+
+	id, err := client.GetFirstModemID(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to get first modem ID: %v", err)
+	}
+	if id != "4" {
+		t.Errorf("Expected modem ID 4, got %s", id)
+	}
+
+	mm, err := client.GetModemDetails(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to get modem details: %v", err)
+	}
+	if !mm.IsConnected() {
+		t.Error("Expected modem to be connected")
+	}
+
+	expected := [][]string{{"-J", "-L"}, {"-m", "4", "-J"}}
+	if !reflect.DeepEqual(runner.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, runner.calls)
+	}
+}
+
+func TestNewClientOptions(t *testing.T) {
+	if _, ok := NewClient(nil).Runner().(ExecRunner); !ok {
+		t.Error("Expected a nil runner to select ExecRunner")
+	}
+
+	runner := &scriptedRunner{}
+	client := NewClient(nil, WithRunner(runner), WithFormat(FormatKeyValue))
+	if client.Runner() != runner || client.Format() != FormatKeyValue {
+		t.Errorf("Expected options to apply, got runner %T and format %v", client.Runner(), client.Format())
+	}
+}
+
+func TestClientNonZeroExit(t *testing.T) {
+	client := NewClient(&scriptedRunner{})
+
+	err := client.Disconnect(context.Background(), "0")
+	if err == nil {
+		t.Fatal("Expected an error for a failing invocation")
+	}
+	if !strings.Contains(err.Error(), "unexpected invocation") {
+		t.Errorf("Expected stderr in error message, got %v", err)
+	}
+}
+
+func TestClientCreateSMS(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		`-m 0 --messaging-create-sms="number=+1234567890,text=hello"`: {
+			Stdout: []byte("Successfully created new SMS: /org/freedesktop/ModemManager1/SMS/7\n"),
+		},
+		"-s 7 --send": {},
+	}}
+	client := NewClient(runner)
+
+	if err := client.CreateAndSendSMS(context.Background(), "0", "+1234567890", "hello"); err != nil {
+		t.Fatalf("Failed to create and send SMS: %v", err)
+	}
+	if len(runner.calls) != 2 {
+		t.Errorf("Expected 2 calls, got %d", len(runner.calls))
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	res, err := ExecRunner{Path: "sh"}.Run(context.Background(), "-c", "echo out; echo err >&2; exit 3")
+	if err != nil {
+		t.Skip("sh not available:", err)
+	}
+	if string(res.Stdout) != "out\n" {
+		t.Errorf("Expected stdout 'out', got %q", res.Stdout)
+	}
+	if string(res.Stderr) != "err\n" {
+		t.Errorf("Expected stderr 'err', got %q", res.Stderr)
+	}
+	if res.ExitCode != 3 {
+		t.Errorf("Expected exit code 3, got %d", res.ExitCode)
+	}
+}
+
+func TestExecRunnerCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := ExecRunner{Path: "sh"}.Run(ctx, "-c", "exec sleep 10")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected child to be killed promptly, took %v", elapsed)
+	}
+}
+
+func TestDeadlineMapsToTimeout(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-m 0 --simple-connect --timeout=29": {},
+	}}
+	client := NewClient(runner)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, "0", ConnectSettings{}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	// An explicit timeout must not be overridden
+	args := withTimeout(ctx, []string{"-m", "0", "--3gpp-scan", "--timeout=120"})
+	if args[len(args)-1] != "--timeout=120" {
+		t.Errorf("Expected explicit timeout to be kept, got %v", args)
+	}
+
+	// Without a deadline the arguments are passed through unchanged
+	if args := withTimeout(context.Background(), []string{"-L"}); len(args) != 1 {
+		t.Errorf("Expected no timeout without deadline, got %v", args)
+	}
+}
+
+func TestCancelledContext(t *testing.T) {
+	runner := &scriptedRunner{}
+	client := NewClient(runner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.ListModems(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("Expected no mmcli invocation, got %v", runner.calls)
+	}
+}
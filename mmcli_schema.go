@@ -0,0 +1,244 @@
+package mmcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FiveGNR contains the 5G NR specific 3GPP information
+type FiveGNR struct {
+	RegistrationSettings NR5GRegistrationSettings `json:"registration-settings"`
+}
+
+// NR5GRegistrationSettings contains the settings used when registering with a 5G network
+type NR5GRegistrationSettings struct {
+	DRXCycle string `json:"drx-cycle"`
+	MICOMode string `json:"mico-mode"`
+}
+
+// NetworkRejection describes why the network last rejected a registration
+type NetworkRejection struct {
+	AccessTechnology string `json:"access-technology"`
+	Error            string `json:"error"`
+	OperatorID       string `json:"operator-id"`
+	OperatorName     string `json:"operator-name"`
+}
+
+// SignalInfo contains the extended signal information printed by mmcli --signal-get
+type SignalInfo struct {
+	Refresh   SignalRefresh   `json:"refresh"`
+	Threshold SignalThreshold `json:"threshold"`
+	CDMA1x    SignalValues    `json:"cdma1x"`
+	EVDO      SignalValues    `json:"evdo"`
+	GSM       SignalValues    `json:"gsm"`
+	UMTS      SignalValues    `json:"umts"`
+	LTE       SignalValues    `json:"lte"`
+	NR5G      SignalValues    `json:"5g"`
+}
+
+// SignalRefresh contains the interval at which the extended signal information is polled
+type SignalRefresh struct {
+	Rate string `json:"rate"`
+}
+
+// SignalThreshold contains the changes that trigger an extended signal information update
+type SignalThreshold struct {
+	RSSI      string `json:"rssi"`
+	ErrorRate string `json:"error-rate"`
+}
+
+// SignalValues contains the signal values of one access technology. mmcli
+// only prints the values that apply to the technology.
+type SignalValues struct {
+	ECIO      string `json:"ecio,omitempty"`
+	ErrorRate string `json:"error-rate,omitempty"`
+	IO        string `json:"io,omitempty"`
+	RSCP      string `json:"rscp,omitempty"`
+	RSRP      string `json:"rsrp,omitempty"`
+	RSRQ      string `json:"rsrq,omitempty"`
+	RSSI      string `json:"rssi,omitempty"`
+	SINR      string `json:"sinr,omitempty"`
+	SNR       string `json:"snr,omitempty"`
+}
+
+// FirmwareInfo contains the firmware information printed by mmcli
+// --firmware-status and --firmware-list
+type FirmwareInfo struct {
+	DeviceIDs []string          `json:"device-ids,omitempty"`
+	Fastboot  *FirmwareFastboot `json:"fastboot,omitempty"`
+	List      []string          `json:"list,omitempty"`
+	Method    []string          `json:"method,omitempty"`
+	Version   string            `json:"version,omitempty"`
+}
+
+// FirmwareFastboot contains the settings of the fastboot update method
+type FirmwareFastboot struct {
+	AT string `json:"at"`
+}
+
+// ModemLocation is the location object sharing the modem root. mmcli
+// --location-status prints the status and --location-get the location into
+// the same object, so both are decoded from it.
+type ModemLocation struct {
+	Status LocationStatus `json:"-"`
+	Info   LocationInfo   `json:"-"`
+}
+
+// UnmarshalJSON decodes both the location status and the location
+func (l *ModemLocation) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &l.Status); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &l.Info)
+}
+
+// MarshalJSON encodes the location status and the location into one object
+func (l ModemLocation) MarshalJSON() ([]byte, error) {
+	status, err := json.Marshal(l.Status)
+	if err != nil {
+		return nil, err
+	}
+	info, err := json.Marshal(l.Info)
+	if err != nil {
+		return nil, err
+	}
+	return mergeObjects(status, info)
+}
+
+func (ModemLocation) decodedTypes() []reflect.Type {
+	return []reflect.Type{reflect.TypeOf(LocationStatus{}), reflect.TypeOf(LocationInfo{})}
+}
+
+// multiDecoder is implemented by types decoding one JSON object into several structs
+type multiDecoder interface {
+	decodedTypes() []reflect.Type
+}
+
+// UnmarshalJSON decodes the modem and keeps the values the structs do not
+// model in Unknown
+func (mm *ModemManager) UnmarshalJSON(data []byte) error {
+	type plain ModemManager
+	if err := json.Unmarshal(data, (*plain)(mm)); err != nil {
+		return err
+	}
+
+	unknown := make(map[string]json.RawMessage)
+	collectUnknown(data, []reflect.Type{reflect.TypeOf(*mm)}, "", unknown)
+
+	mm.Unknown = nil
+	if len(unknown) > 0 {
+		mm.Unknown = unknown
+	}
+	return nil
+}
+
+// MarshalJSON encodes the modem including the values kept in Unknown
+func (mm ModemManager) MarshalJSON() ([]byte, error) {
+	type plain ModemManager
+	data, err := json.Marshal(plain(mm))
+	if err != nil || len(mm.Unknown) == 0 {
+		return data, err
+	}
+
+	paths := make([]string, 0, len(mm.Unknown))
+	for path := range mm.Unknown {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		value := mm.Unknown[path]
+		keys := strings.Split(path, ".")
+		for i := len(keys) - 1; i >= 0; i-- {
+			if value, err = json.Marshal(map[string]json.RawMessage{keys[i]: value}); err != nil {
+				return nil, err
+			}
+		}
+		if data, err = mergeObjects(data, value); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// collectUnknown adds the values of the JSON object in data that none of the
+// struct types decodes to unknown, keyed by prefix and their key
+func collectUnknown(data []byte, types []reflect.Type, prefix string, unknown map[string]json.RawMessage) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return
+	}
+
+	known := make(map[string][]reflect.Type)
+	for _, t := range types {
+		jsonFields(t, known)
+	}
+
+	for key, value := range object {
+		fieldTypes, ok := known[key]
+		if !ok {
+			unknown[prefix+key] = compact(value)
+			continue
+		}
+		var structs []reflect.Type
+		for _, t := range fieldTypes {
+			if t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if d, ok := reflect.Zero(t).Interface().(multiDecoder); ok {
+				structs = append(structs, d.decodedTypes()...)
+			} else if t.Kind() == reflect.Struct {
+				structs = append(structs, t)
+			}
+		}
+		if len(structs) > 0 {
+			collectUnknown(value, structs, prefix+key+".", unknown)
+		}
+	}
+}
+
+// jsonFields adds the JSON keys of the struct type t and the types they decode to to fields
+func jsonFields(t reflect.Type, fields map[string][]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = append(fields[name], f.Type)
+	}
+}
+
+// mergeObjects merges the JSON objects a and b. Keys present in both are
+// merged if both values are objects, otherwise b wins.
+func mergeObjects(a, b []byte) ([]byte, error) {
+	var objectA, objectB map[string]json.RawMessage
+	if json.Unmarshal(a, &objectA) != nil || json.Unmarshal(b, &objectB) != nil || objectA == nil {
+		return b, nil
+	}
+	for key, value := range objectB {
+		if old, ok := objectA[key]; ok {
+			merged, err := mergeObjects(old, value)
+			if err != nil {
+				return nil, err
+			}
+			value = merged
+		}
+		objectA[key] = value
+	}
+	return json.Marshal(objectA)
+}
+
+func compact(value json.RawMessage) json.RawMessage {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, value); err != nil {
+		return value
+	}
+	return buf.Bytes()
+}
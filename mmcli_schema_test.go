@@ -0,0 +1,153 @@
+package mmcli
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestModemSchema(t *testing.T) {
+	data := `{"modem": {
+		"3gpp": {
+			"5gnr": {"registration-settings": {"drx-cycle": "64", "mico-mode": "disabled"}},
+			"network-rejection": {"access-technology": "lte", "error": "roaming-not-allowed", "operator-id": "26202", "operator-name": "Vodafone.de"},
+			"packet-service-state": "attached"
+		},
+		"generic": {
+			"carrier-configuration-revision": "0501081F",
+			"max-active-bearers": "1",
+			"max-active-multiplexed-bearers": "--",
+			"max-bearers": "2",
+			"primary-sim-slot": "1",
+			"sim-slots": ["/org/freedesktop/ModemManager1/SIM/0", "/"]
+		}
+	}}`
+	mm, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	threegpp := mm.Modem.ThreeGPP
+	if threegpp.FiveGNR.RegistrationSettings != (NR5GRegistrationSettings{DRXCycle: "64", MICOMode: "disabled"}) {
+		t.Errorf("Unexpected 5G registration settings %+v", threegpp.FiveGNR.RegistrationSettings)
+	}
+	if threegpp.NetworkRejection.Error != "roaming-not-allowed" || threegpp.NetworkRejection.OperatorID != "26202" {
+		t.Errorf("Unexpected network rejection %+v", threegpp.NetworkRejection)
+	}
+	if threegpp.PacketServiceState != "attached" {
+		t.Errorf("Expected packet service attached, got %q", threegpp.PacketServiceState)
+	}
+
+	g := mm.Modem.Generic
+	if g.MaxBearers != "2" || g.MaxActiveBearers != "1" || IsSet(g.MaxActiveMultiplexedBearers) {
+		t.Errorf("Unexpected bearer limits %q, %q, %q", g.MaxBearers, g.MaxActiveBearers, g.MaxActiveMultiplexedBearers)
+	}
+	if g.PrimarySIMSlot != "1" || len(g.SIMSlots) != 2 || g.CarrierConfigurationRevision != "0501081F" {
+		t.Errorf("Unexpected generic info %+v", g)
+	}
+	if mm.Unknown != nil {
+		t.Errorf("Expected no unknown values, got %s", mm.Unknown)
+	}
+}
+
+func TestModemSubObjects(t *testing.T) {
+	data := `{"modem": {
+		"signal": {
+			"refresh": {"rate": "10"},
+			"threshold": {"rssi": "--", "error-rate": "--"},
+			"lte": {"rsrp": "-95.00", "rsrq": "-11.00", "rssi": "-65.00", "snr": "8.40", "error-rate": "--"},
+			"5g": {"rsrp": "-88.00", "rsrq": "-10.00", "snr": "12.00", "error-rate": "--"}
+		},
+		"firmware": {"method": ["fastboot"], "fastboot": {"at": "AT+QFASTBOOT"}, "device-ids": ["USB\\VID_2C7C&PID_0125"], "version": "EC25EFAR06A06M4G"},
+		"location": {
+			"capabilities": ["3gpp-lac-ci", "gps-raw"],
+			"enabled": ["3gpp-lac-ci"],
+			"signals": "yes",
+			"gps": {"refresh-rate": "30", "latitude": "52.520008", "longitude": "13.404954"},
+			"3gpp": {"mcc": "262", "mnc": "01", "lac": "FFFE", "cid": "01A2B3C4", "tac": "--"}
+		}
+	}}`
+	mm, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	signal := mm.Modem.Signal
+	if signal == nil {
+		t.Fatal("Expected signal information")
+	}
+	if signal.Refresh.Rate != "10" || signal.LTE.RSRP != "-95.00" || signal.NR5G.SNR != "12.00" {
+		t.Errorf("Unexpected signal information %+v", signal)
+	}
+
+	firmware := mm.Modem.Firmware
+	if firmware == nil || firmware.Version != "EC25EFAR06A06M4G" || firmware.Fastboot == nil || firmware.Fastboot.AT != "AT+QFASTBOOT" {
+		t.Errorf("Unexpected firmware %+v", firmware)
+	}
+
+	location := mm.Modem.Location
+	if location == nil {
+		t.Fatal("Expected location")
+	}
+	if !location.Status.SignalsEnabled || location.Status.GPS.RefreshRate != "30" {
+		t.Errorf("Unexpected location status %+v", location.Status)
+	}
+	if location.Info.ThreeGPP.CIDNumber == nil || *location.Info.ThreeGPP.CIDNumber != 0x01A2B3C4 ||
+		location.Info.GPS.LatitudeDegrees == nil {
+		t.Errorf("Unexpected location %+v", location.Info)
+	}
+	if mm.Unknown != nil {
+		t.Errorf("Expected no unknown values, got %s", mm.Unknown)
+	}
+
+	out, err := json.Marshal(location)
+	if err != nil {
+		t.Fatalf("Failed to marshal location: %v", err)
+	}
+	var decoded ModemLocation
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal %s: %v", out, err)
+	}
+	if !reflect.DeepEqual(&decoded, location) {
+		t.Errorf("Round trip changed %+v to %+v", location, decoded)
+	}
+}
+
+func TestModemUnknownValues(t *testing.T) {
+	data := `{"modem": {
+		"dbus-path": "/org/freedesktop/ModemManager1/Modem/0",
+		"generic": {"model": "EC25", "thermal-state": "normal", "signal-quality": {"value": "67", "recent": "yes", "source": "rssi"}},
+		"3gpp": {"eps": {"initial-bearer": {"settings": {"apn": "internet", "profile-id": "1"}}}},
+		"sar": {"state": "off", "power-level": "0"}
+	}, "version": "1.24.0"}`
+	mm, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expected := map[string]json.RawMessage{
+		"modem.generic.thermal-state":                       json.RawMessage(`"normal"`),
+		"modem.generic.signal-quality.source":               json.RawMessage(`"rssi"`),
+		"modem.3gpp.eps.initial-bearer.settings.profile-id": json.RawMessage(`"1"`),
+		"modem.sar": json.RawMessage(`{"state":"off","power-level":"0"}`),
+		"version":   json.RawMessage(`"1.24.0"`),
+	}
+	if !reflect.DeepEqual(mm.Unknown, expected) {
+		t.Errorf("Expected unknown values %s, got %s", expected, mm.Unknown)
+	}
+	if mm.Modem.Generic.Model != "EC25" || mm.Modem.ThreeGPP.EPS.InitialBearer.Settings.APN != "internet" {
+		t.Errorf("Expected the known values to be decoded, got %+v", mm.Modem)
+	}
+
+	out, err := json.Marshal(mm)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	var decoded ModemManager
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal %s: %v", out, err)
+	}
+	if !reflect.DeepEqual(&decoded, mm) {
+		t.Errorf("Round trip changed %+v to %+v", mm, decoded)
+	}
+}
@@ -0,0 +1,69 @@
+package mmcli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// GetSignal returns the extended signal information. The modem only reports
+// values after polling was enabled with SetupSignal.
+func GetSignal(modemID string) (*SignalInfo, error) {
+	return GetSignalContext(context.Background(), modemID)
+}
+
+// GetSignalContext is like GetSignal but uses ctx to bound the mmcli invocation
+func GetSignalContext(ctx context.Context, modemID string) (*SignalInfo, error) {
+	return DefaultClient.GetSignal(ctx, modemID)
+}
+
+// GetSignal returns the extended signal information. The modem only reports
+// values after polling was enabled with SetupSignal.
+func (c *Client) GetSignal(ctx context.Context, modemID string) (*SignalInfo, error) {
+	out, err := c.run(ctx, "-m", modemID, "--signal-get", c.output())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal information: %w", err)
+	}
+
+	var response struct {
+		Modem struct {
+			Signal SignalInfo `json:"signal"`
+		} `json:"modem"`
+	}
+
+	if err := c.unmarshal(out, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse signal information: %w", err)
+	}
+
+	return &response.Modem.Signal, nil
+}
+
+// SetupSignal enables polling the extended signal information every rate
+// seconds. A rate of 0 disables polling.
+func SetupSignal(modemID string, rate int) error {
+	return SetupSignalContext(context.Background(), modemID, rate)
+}
+
+// SetupSignalContext is like SetupSignal but uses ctx to bound the mmcli invocation
+func SetupSignalContext(ctx context.Context, modemID string, rate int) error {
+	return DefaultClient.SetupSignal(ctx, modemID, rate)
+}
+
+// SetupSignal enables polling the extended signal information every rate
+// seconds. A rate of 0 disables polling.
+func (c *Client) SetupSignal(ctx context.Context, modemID string, rate int) error {
+	_, err := c.run(ctx, "-m", modemID, fmt.Sprintf("--signal-setup=%d", rate))
+	if err != nil {
+		return fmt.Errorf("failed to set up signal polling: %w", err)
+	}
+	return nil
+}
+
+// signalValue decodes a signal value, which mmcli may print with its unit,
+// e.g. "-95.00 dBm"
+func signalValue(s string) *float64 {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return optionalFloat(fields[0])
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package mmcli
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetSignal(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-m 0 --signal-setup=10": {},
+		"-m 0 --signal-get -J": {Stdout: []byte(`{"modem": {"signal": {
+			"refresh": {"rate": "10"},
+			"threshold": {"rssi": "0", "error-rate": "no"},
+			"lte": {"rsrp": "-95.00", "rsrq": "-11.00", "rssi": "-65.00", "snr": "8.40", "error-rate": "--"}
+		}}}`)},
+	}}
+	client := NewClient(runner)
+	ctx := context.Background()
+
+	if err := client.SetupSignal(ctx, "0", 10); err != nil {
+		t.Fatalf("Failed to set up signal polling: %v", err)
+	}
+	signal, err := client.GetSignal(ctx, "0")
+	if err != nil {
+		t.Fatalf("Failed to get signal: %v", err)
+	}
+	if signal.Refresh.Rate != "10" || signal.LTE.RSRP != "-95.00" || signal.LTE.ErrorRate != "--" {
+		t.Errorf("Unexpected signal %+v", signal)
+	}
+}
+
+func TestSignalValue(t *testing.T) {
+	for s, want := range map[string]float64{"-95.00": -95, "-95.50 dBm": -95.5, "8.4 dB": 8.4} {
+		if got := signalValue(s); got == nil || *got != want {
+			t.Errorf("signalValue(%q) = %v, expected %v", s, got, want)
+		}
+	}
+	for _, s := range []string{"--", "", "n/a"} {
+		if got := signalValue(s); got != nil {
+			t.Errorf("signalValue(%q) = %v, expected nil", s, *got)
+		}
+	}
+}
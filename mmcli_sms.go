@@ -1,10 +1,10 @@
 package mmcli
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os/exec"
 	"strings"
+	"time"
 )
 
 // MessagingStatus represents the status of messaging support
@@ -40,6 +40,11 @@ type SMSProperties struct {
 	Timestamp          string `json:"timestamp"`
 	Validity           string `json:"validity"`
 	Data               []byte `json:"data"`
+
+	// Typed values decoded from the strings above, nil if unset
+	ValidityMinutes *int       `json:"-"`
+	Time            *time.Time `json:"-"`
+	DischargeTime   *time.Time `json:"-"`
 }
 
 // SMSCreateSettings represents the settings for creating a new SMS
@@ -54,7 +59,17 @@ type SMSCreateSettings struct {
 
 // GetMessagingStatus returns the status of messaging support
 func GetMessagingStatus(modemID string) (*MessagingStatus, error) {
-	out, err := exec.Command("mmcli", "-m", modemID, "--messaging-status", "-J").Output()
+	return GetMessagingStatusContext(context.Background(), modemID)
+}
+
+// GetMessagingStatusContext is like GetMessagingStatus but uses ctx to bound the mmcli invocation
+func GetMessagingStatusContext(ctx context.Context, modemID string) (*MessagingStatus, error) {
+	return DefaultClient.GetMessagingStatus(ctx, modemID)
+}
+
+// GetMessagingStatus returns the status of messaging support
+func (c *Client) GetMessagingStatus(ctx context.Context, modemID string) (*MessagingStatus, error) {
+	out, err := c.run(ctx, "-m", modemID, "--messaging-status", c.output())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get messaging status: %w", err)
 	}
@@ -65,7 +80,7 @@ func GetMessagingStatus(modemID string) (*MessagingStatus, error) {
 		} `json:"modem"`
 	}
 
-	if err := json.Unmarshal(out, &response); err != nil {
+	if err := c.unmarshal(out, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse messaging status: %w", err)
 	}
 
@@ -74,13 +89,23 @@ func GetMessagingStatus(modemID string) (*MessagingStatus, error) {
 
 // ListSMS returns a list of SMS messages
 func ListSMS(modemID string) ([]string, error) {
-	out, err := exec.Command("mmcli", "-m", modemID, "--messaging-list-sms", "-J").Output()
+	return ListSMSContext(context.Background(), modemID)
+}
+
+// ListSMSContext is like ListSMS but uses ctx to bound the mmcli invocation
+func ListSMSContext(ctx context.Context, modemID string) ([]string, error) {
+	return DefaultClient.ListSMS(ctx, modemID)
+}
+
+// ListSMS returns a list of SMS messages
+func (c *Client) ListSMS(ctx context.Context, modemID string) ([]string, error) {
+	out, err := c.run(ctx, "-m", modemID, "--messaging-list-sms", c.output())
 	if err != nil {
 		return nil, fmt.Errorf("failed to list SMS messages: %w", err)
 	}
 
 	var list SMSList
-	if err := json.Unmarshal(out, &list); err != nil {
+	if err := c.unmarshal(out, &list); err != nil {
 		return nil, fmt.Errorf("failed to parse SMS list: %w", err)
 	}
 
@@ -89,7 +114,17 @@ func ListSMS(modemID string) ([]string, error) {
 
 // GetSMSInfo returns information about a specific SMS message
 func GetSMSInfo(smsID string) (*SMSInfo, error) {
-	out, err := exec.Command("mmcli", "-s", smsID, "-J").Output()
+	return GetSMSInfoContext(context.Background(), smsID)
+}
+
+// GetSMSInfoContext is like GetSMSInfo but uses ctx to bound the mmcli invocation
+func GetSMSInfoContext(ctx context.Context, smsID string) (*SMSInfo, error) {
+	return DefaultClient.GetSMSInfo(ctx, smsID)
+}
+
+// GetSMSInfo returns information about a specific SMS message
+func (c *Client) GetSMSInfo(ctx context.Context, smsID string) (*SMSInfo, error) {
+	out, err := c.run(ctx, "-s", smsID, c.output())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get SMS info: %w", err)
 	}
@@ -98,7 +133,7 @@ func GetSMSInfo(smsID string) (*SMSInfo, error) {
 		SMS SMSInfo `json:"sms"`
 	}
 
-	if err := json.Unmarshal(out, &response); err != nil {
+	if err := c.unmarshal(out, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse SMS info: %w", err)
 	}
 
@@ -107,6 +142,16 @@ func GetSMSInfo(smsID string) (*SMSInfo, error) {
 
 // CreateSMS creates a new SMS message
 func CreateSMS(modemID string, settings SMSCreateSettings) (string, error) {
+	return CreateSMSContext(context.Background(), modemID, settings)
+}
+
+// CreateSMSContext is like CreateSMS but uses ctx to bound the mmcli invocation
+func CreateSMSContext(ctx context.Context, modemID string, settings SMSCreateSettings) (string, error) {
+	return DefaultClient.CreateSMS(ctx, modemID, settings)
+}
+
+// CreateSMS creates a new SMS message
+func (c *Client) CreateSMS(ctx context.Context, modemID string, settings SMSCreateSettings) (string, error) {
 	// Build the settings string
 	var settingsParams []string
 	if settings.Number != "" {
@@ -136,7 +181,7 @@ func CreateSMS(modemID string, settings SMSCreateSettings) (string, error) {
 	args = append(args, fmt.Sprintf("--messaging-create-sms=\"%s\"", settingsStr))
 
 	// Execute the command
-	out, err := exec.Command("mmcli", args...).Output()
+	out, err := c.run(ctx, args...)
 	if err != nil {
 		return "", fmt.Errorf("failed to create SMS: %w", err)
 	}
@@ -163,7 +208,17 @@ func CreateSMS(modemID string, settings SMSCreateSettings) (string, error) {
 
 // SendSMS sends an SMS message
 func SendSMS(smsID string) error {
-	_, err := exec.Command("mmcli", "-s", smsID, "--send").Output()
+	return SendSMSContext(context.Background(), smsID)
+}
+
+// SendSMSContext is like SendSMS but uses ctx to bound the mmcli invocation
+func SendSMSContext(ctx context.Context, smsID string) error {
+	return DefaultClient.SendSMS(ctx, smsID)
+}
+
+// SendSMS sends an SMS message
+func (c *Client) SendSMS(ctx context.Context, smsID string) error {
+	_, err := c.run(ctx, "-s", smsID, "--send")
 	if err != nil {
 		return fmt.Errorf("failed to send SMS: %w", err)
 	}
@@ -173,7 +228,17 @@ func SendSMS(smsID string) error {
 
 // StoreSMS stores an SMS message in the device
 func StoreSMS(smsID string) error {
-	_, err := exec.Command("mmcli", "-s", smsID, "--store").Output()
+	return StoreSMSContext(context.Background(), smsID)
+}
+
+// StoreSMSContext is like StoreSMS but uses ctx to bound the mmcli invocation
+func StoreSMSContext(ctx context.Context, smsID string) error {
+	return DefaultClient.StoreSMS(ctx, smsID)
+}
+
+// StoreSMS stores an SMS message in the device
+func (c *Client) StoreSMS(ctx context.Context, smsID string) error {
+	_, err := c.run(ctx, "-s", smsID, "--store")
 	if err != nil {
 		return fmt.Errorf("failed to store SMS: %w", err)
 	}
@@ -183,7 +248,17 @@ func StoreSMS(smsID string) error {
 
 // StoreSMSInStorage stores an SMS message in the specified storage
 func StoreSMSInStorage(smsID string, storage string) error {
-	_, err := exec.Command("mmcli", "-s", smsID, fmt.Sprintf("--store-in-storage=%s", storage)).Output()
+	return StoreSMSInStorageContext(context.Background(), smsID, storage)
+}
+
+// StoreSMSInStorageContext is like StoreSMSInStorage but uses ctx to bound the mmcli invocation
+func StoreSMSInStorageContext(ctx context.Context, smsID string, storage string) error {
+	return DefaultClient.StoreSMSInStorage(ctx, smsID, storage)
+}
+
+// StoreSMSInStorage stores an SMS message in the specified storage
+func (c *Client) StoreSMSInStorage(ctx context.Context, smsID string, storage string) error {
+	_, err := c.run(ctx, "-s", smsID, fmt.Sprintf("--store-in-storage=%s", storage))
 	if err != nil {
 		return fmt.Errorf("failed to store SMS in storage %s: %w", storage, err)
 	}
@@ -193,7 +268,17 @@ func StoreSMSInStorage(smsID string, storage string) error {
 
 // DeleteSMS deletes an SMS message
 func DeleteSMS(modemID string, smsID string) error {
-	_, err := exec.Command("mmcli", "-m", modemID, fmt.Sprintf("--messaging-delete-sms=%s", smsID)).Output()
+	return DeleteSMSContext(context.Background(), modemID, smsID)
+}
+
+// DeleteSMSContext is like DeleteSMS but uses ctx to bound the mmcli invocation
+func DeleteSMSContext(ctx context.Context, modemID string, smsID string) error {
+	return DefaultClient.DeleteSMS(ctx, modemID, smsID)
+}
+
+// DeleteSMS deletes an SMS message
+func (c *Client) DeleteSMS(ctx context.Context, modemID string, smsID string) error {
+	_, err := c.run(ctx, "-m", modemID, fmt.Sprintf("--messaging-delete-sms=%s", smsID))
 	if err != nil {
 		return fmt.Errorf("failed to delete SMS: %w", err)
 	}
@@ -203,15 +288,25 @@ func DeleteSMS(modemID string, smsID string) error {
 
 // CreateAndSendSMS creates and sends an SMS message in one step
 func CreateAndSendSMS(modemID string, number string, text string) error {
+	return CreateAndSendSMSContext(context.Background(), modemID, number, text)
+}
+
+// CreateAndSendSMSContext is like CreateAndSendSMS but uses ctx to bound the mmcli invocation
+func CreateAndSendSMSContext(ctx context.Context, modemID string, number string, text string) error {
+	return DefaultClient.CreateAndSendSMS(ctx, modemID, number, text)
+}
+
+// CreateAndSendSMS creates and sends an SMS message in one step
+func (c *Client) CreateAndSendSMS(ctx context.Context, modemID string, number string, text string) error {
 	settings := SMSCreateSettings{
 		Number: number,
 		Text:   text,
 	}
 
-	smsID, err := CreateSMS(modemID, settings)
+	smsID, err := c.CreateSMS(ctx, modemID, settings)
 	if err != nil {
 		return err
 	}
 
-	return SendSMS(smsID)
+	return c.SendSMS(ctx, smsID)
 }
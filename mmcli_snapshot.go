@@ -0,0 +1,365 @@
+package mmcli
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SnapshotVersion is the version of the Snapshot schema. It is increased
+// whenever a field is removed or changes its meaning; fields may be added
+// without increasing it.
+const SnapshotVersion = 1
+
+// Snapshot is a normalized, versioned export of a modem's state, meant to be
+// shipped off the device. Unlike the mmcli structs its JSON field names,
+// units and enum values are fixed and do not depend on the ModemManager
+// version. Values that are not known are omitted.
+type Snapshot struct {
+	Version int       `json:"version"`  // always SnapshotVersion
+	TakenAt time.Time `json:"taken_at"` // RFC 3339
+
+	Modem    SnapshotModem     `json:"modem"`
+	Network  SnapshotNetwork   `json:"network"`
+	Signal   SnapshotSignal    `json:"signal"`
+	SIM      *SnapshotSIM      `json:"sim,omitempty"`
+	Location *SnapshotLocation `json:"location,omitempty"`
+	Time     *SnapshotTime     `json:"time,omitempty"`
+}
+
+// SnapshotModem identifies the modem and describes its state
+type SnapshotModem struct {
+	DBusPath         string   `json:"dbus_path,omitempty"`
+	DeviceID         string   `json:"device_id,omitempty"`
+	IMEI             string   `json:"imei,omitempty"` // equipment identifier
+	Manufacturer     string   `json:"manufacturer,omitempty"`
+	Model            string   `json:"model,omitempty"`
+	Firmware         string   `json:"firmware,omitempty"` // firmware revision
+	HardwareRevision string   `json:"hardware_revision,omitempty"`
+	Plugin           string   `json:"plugin,omitempty"`
+	Drivers          []string `json:"drivers,omitempty"`
+
+	// State is one of the ModemState names, e.g. "registered" or "connected"
+	State ModemState `json:"state"`
+	// FailedReason is one of the StateFailedReason names, set if State is "failed"
+	FailedReason *StateFailedReason `json:"failed_reason,omitempty"`
+	// PowerState is "off", "low" or "on"
+	PowerState string         `json:"power_state,omitempty"`
+	Ports      []SnapshotPort `json:"ports,omitempty"`
+}
+
+// SnapshotPort is a modem port
+type SnapshotPort struct {
+	Name string `json:"name"`
+	// Type is one of the PortType names, e.g. "net", "at" or "qmi"
+	Type string `json:"type"`
+}
+
+// SnapshotNetwork describes the network registration
+type SnapshotNetwork struct {
+	// Registration is the 3GPP registration state as printed by mmcli, e.g.
+	// "home", "roaming" or "searching"
+	Registration string `json:"registration,omitempty"`
+	OperatorName string `json:"operator_name,omitempty"`
+	OperatorCode string `json:"operator_code,omitempty"` // MCC and MNC, e.g. "26201"
+	// AccessTechnologies are AccessTechnology names, e.g. ["lte", "5gnr"]
+	AccessTechnologies []string `json:"access_technologies,omitempty"`
+	// Generation is one of the Generation names, e.g. "4G" or "5G NSA", and
+	// omitted if unknown
+	Generation string `json:"generation,omitempty"`
+	// Bands are the Band names the modem is allowed to use, e.g. "eutran-3"
+	Bands []string `json:"bands,omitempty"`
+	// PacketService is "attached" or "detached"
+	PacketService string `json:"packet_service,omitempty"`
+}
+
+// SnapshotSignal describes the signal. The per-technology values are only
+// present if extended signal information was collected.
+type SnapshotSignal struct {
+	QualityPercent *int                  `json:"quality_percent,omitempty"`
+	Recent         bool                  `json:"recent"`
+	GSM            *SnapshotSignalValues `json:"gsm,omitempty"`
+	UMTS           *SnapshotSignalValues `json:"umts,omitempty"`
+	LTE            *SnapshotSignalValues `json:"lte,omitempty"`
+	NR5G           *SnapshotSignalValues `json:"nr5g,omitempty"`
+}
+
+// SnapshotSignalValues are the signal values of one access technology
+type SnapshotSignalValues struct {
+	RSSIDBm          *float64 `json:"rssi_dbm,omitempty"`
+	RSCPDBm          *float64 `json:"rscp_dbm,omitempty"`
+	ECIODB           *float64 `json:"ecio_db,omitempty"`
+	IODBm            *float64 `json:"io_dbm,omitempty"`
+	RSRPDBm          *float64 `json:"rsrp_dbm,omitempty"`
+	RSRQDB           *float64 `json:"rsrq_db,omitempty"`
+	SNRDB            *float64 `json:"snr_db,omitempty"`
+	SINRDB           *float64 `json:"sinr_db,omitempty"`
+	ErrorRatePercent *float64 `json:"error_rate_percent,omitempty"`
+}
+
+// SnapshotSIM describes the SIM card
+type SnapshotSIM struct {
+	DBusPath     string `json:"dbus_path,omitempty"`
+	ICCID        string `json:"iccid,omitempty"`
+	IMSI         string `json:"imsi,omitempty"`
+	EID          string `json:"eid,omitempty"`
+	OperatorName string `json:"operator_name,omitempty"`
+	OperatorCode string `json:"operator_code,omitempty"`
+	Active       bool   `json:"active"`
+	// Lock is the Lock name that must be unlocked, e.g. "sim-pin", or "none"
+	Lock string `json:"lock,omitempty"`
+	// UnlockRetries maps Lock names to the remaining attempts
+	UnlockRetries map[string]int `json:"unlock_retries,omitempty"`
+}
+
+// SnapshotLocation is the modem's location
+type SnapshotLocation struct {
+	MCC    string  `json:"mcc,omitempty"`
+	MNC    string  `json:"mnc,omitempty"`
+	LAC    *uint64 `json:"lac,omitempty"`
+	CellID *uint64 `json:"cell_id,omitempty"`
+	TAC    *uint64 `json:"tac,omitempty"`
+
+	LatitudeDegrees  *float64 `json:"latitude_deg,omitempty"`
+	LongitudeDegrees *float64 `json:"longitude_deg,omitempty"`
+	AltitudeMeters   *float64 `json:"altitude_m,omitempty"`
+}
+
+// SnapshotTime is the time reported by the network
+type SnapshotTime struct {
+	Network time.Time `json:"network"` // RFC 3339 with the network's offset
+}
+
+// SnapshotData holds the query results a Snapshot is built from. Only Modem
+// is required.
+type SnapshotData struct {
+	Modem    *ModemManager
+	SIM      *SIMInfo
+	Location *LocationInfo
+	Signal   *SignalInfo // defaults to Modem.Modem.Signal
+	Time     *TimeInfo
+}
+
+// NewSnapshot builds a snapshot taken at takenAt from the given query results
+func NewSnapshot(data SnapshotData, takenAt time.Time) *Snapshot {
+	mm := data.Modem
+	if mm == nil {
+		mm = &ModemManager{}
+	}
+	g, threegpp := mm.Modem.Generic, mm.Modem.ThreeGPP
+
+	s := &Snapshot{
+		Version: SnapshotVersion,
+		TakenAt: takenAt,
+		Modem: SnapshotModem{
+			DBusPath:         optional(mm.Modem.DBusPath),
+			DeviceID:         optional(g.DeviceIdentifier),
+			IMEI:             optional(g.EquipmentIdentifier),
+			Manufacturer:     optional(g.Manufacturer),
+			Model:            optional(g.Model),
+			Firmware:         optional(g.Revision),
+			HardwareRevision: optional(g.HardwareRevision),
+			Plugin:           optional(g.Plugin),
+			Drivers:          g.Drivers,
+			State:            mm.State(),
+			PowerState:       optional(g.PowerState),
+		},
+		Network: SnapshotNetwork{
+			Registration:       optional(threegpp.RegistrationState),
+			OperatorName:       optional(threegpp.OperatorName),
+			OperatorCode:       optional(threegpp.OperatorCode),
+			AccessTechnologies: mm.AccessTechnologies().Strings(),
+			Generation:         snapshotGeneration(mm.AccessTechnologies().Generation()),
+			Bands:              mm.CurrentBands().Strings(),
+			PacketService:      optional(threegpp.PacketServiceState),
+		},
+		Signal: SnapshotSignal{
+			QualityPercent: g.SignalQuality.Percent,
+			Recent:         g.SignalQuality.IsRecent,
+		},
+	}
+
+	if s.Modem.State == ModemStateFailed {
+		reason := mm.FailedReason()
+		s.Modem.FailedReason = &reason
+	}
+	for _, port := range mm.Ports() {
+		s.Modem.Ports = append(s.Modem.Ports, SnapshotPort{Name: port.Name, Type: port.Type.String()})
+	}
+
+	signal := data.Signal
+	if signal == nil {
+		signal = mm.Modem.Signal
+	}
+	if signal != nil {
+		s.Signal.GSM = snapshotSignalValues(signal.GSM)
+		s.Signal.UMTS = snapshotSignalValues(signal.UMTS)
+		s.Signal.LTE = snapshotSignalValues(signal.LTE)
+		s.Signal.NR5G = snapshotSignalValues(signal.NR5G)
+	}
+
+	if data.SIM != nil {
+		s.SIM = newSnapshotSIM(mm, data.SIM)
+	}
+
+	location := data.Location
+	if location == nil && mm.Modem.Location != nil {
+		location = &mm.Modem.Location.Info
+	}
+	if location != nil {
+		s.Location = newSnapshotLocation(location)
+	}
+
+	if data.Time != nil {
+		if t, err := data.Time.Time(); err == nil {
+			s.Time = &SnapshotTime{Network: t}
+		}
+	}
+
+	return s
+}
+
+func snapshotGeneration(g Generation) string {
+	if g == GenerationUnknown {
+		return ""
+	}
+	return g.String()
+}
+
+func snapshotSignalValues(v SignalValues) *SnapshotSignalValues {
+	values := &SnapshotSignalValues{
+		RSSIDBm:          signalValue(v.RSSI),
+		RSCPDBm:          signalValue(v.RSCP),
+		ECIODB:           signalValue(v.ECIO),
+		IODBm:            signalValue(v.IO),
+		RSRPDBm:          signalValue(v.RSRP),
+		RSRQDB:           signalValue(v.RSRQ),
+		SNRDB:            signalValue(v.SNR),
+		SINRDB:           signalValue(v.SINR),
+		ErrorRatePercent: signalValue(v.ErrorRate),
+	}
+	if *values == (SnapshotSignalValues{}) {
+		return nil
+	}
+	return values
+}
+
+func newSnapshotSIM(mm *ModemManager, sim *SIMInfo) *SnapshotSIM {
+	p := sim.Properties
+	s := &SnapshotSIM{
+		DBusPath:     optional(sim.DBusPath),
+		ICCID:        optional(p.ICCID),
+		IMSI:         optional(p.IMSI),
+		EID:          optional(p.EID),
+		OperatorName: optional(p.OperatorName),
+		OperatorCode: optional(p.OperatorCode),
+		Active:       isYes(p.Active),
+	}
+	if lock := mm.UnlockRequired(); lock != LockUnknown {
+		s.Lock = lock.String()
+	}
+	for lock, n := range mm.UnlockRetries() {
+		if s.UnlockRetries == nil {
+			s.UnlockRetries = make(map[string]int)
+		}
+		s.UnlockRetries[lock.String()] = n
+	}
+	return s
+}
+
+func newSnapshotLocation(l *LocationInfo) *SnapshotLocation {
+	location := &SnapshotLocation{
+		MCC:              optional(l.ThreeGPP.MCC),
+		MNC:              optional(l.ThreeGPP.MNC),
+		LAC:              optionalHex(l.ThreeGPP.LAC),
+		CellID:           optionalHex(l.ThreeGPP.CID),
+		TAC:              optionalHex(l.ThreeGPP.TAC),
+		LatitudeDegrees:  optionalFloat(l.GPS.Latitude),
+		LongitudeDegrees: optionalFloat(l.GPS.Longitude),
+		AltitudeMeters:   optionalFloat(l.GPS.Altitude),
+	}
+	if location.LatitudeDegrees == nil {
+		location.LatitudeDegrees = optionalFloat(l.CDMABS.Latitude)
+		location.LongitudeDegrees = optionalFloat(l.CDMABS.Longitude)
+	}
+	if *location == (SnapshotLocation{}) {
+		return nil
+	}
+	return location
+}
+
+// signalGetter is implemented by backends that can query extended signal information
+type signalGetter interface {
+	GetSignal(ctx context.Context, modemID string) (*SignalInfo, error)
+}
+
+// Snapshot queries the modem and builds a snapshot of it. Only the modem
+// details are required; the SIM, location, extended signal and network time
+// are included if the modem provides them.
+func (m *ModemHandle) Snapshot(ctx context.Context) (*Snapshot, error) {
+	mm, err := m.Details(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data := SnapshotData{Modem: mm}
+
+	// The optional parts fail on modems lacking the capability, which is
+	// not an error for the snapshot as a whole
+	queryOptional := func(query func(id string) error) error {
+		err := m.do(ctx, query)
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		return nil
+	}
+
+	if IsSet(mm.Modem.Generic.SIM) {
+		err = queryOptional(func(string) (err error) {
+			data.SIM, err = m.backend.GetSIMInfo(ctx, mm.Modem.Generic.SIM)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = queryOptional(func(id string) (err error) {
+		data.Location, err = m.backend.GetLocation(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if getter, ok := m.backend.(signalGetter); ok {
+		err = queryOptional(func(id string) (err error) {
+			data.Signal, err = getter.GetSignal(ctx, id)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = queryOptional(func(id string) (err error) {
+		data.Time, err = m.backend.GetNetworkTime(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSnapshot(data, time.Now()), nil
+}
+
+// TakeSnapshot queries the modem and builds a snapshot of it, see ModemHandle.Snapshot
+func TakeSnapshot(modemID string) (*Snapshot, error) {
+	return TakeSnapshotContext(context.Background(), modemID)
+}
+
+// TakeSnapshotContext is like TakeSnapshot but uses ctx to bound the mmcli invocations
+func TakeSnapshotContext(ctx context.Context, modemID string) (*Snapshot, error) {
+	return DefaultClient.TakeSnapshot(ctx, modemID)
+}
+
+// TakeSnapshot queries the modem and builds a snapshot of it, see ModemHandle.Snapshot
+func (c *Client) TakeSnapshot(ctx context.Context, modemID string) (*Snapshot, error) {
+	return c.Modem(modemID).Snapshot(ctx)
+}
@@ -0,0 +1,191 @@
+package mmcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const snapshotModemJSON = `{"modem": {
+	"3gpp": {"operator-code": "26201", "operator-name": "Telekom.de", "registration-state": "home", "packet-service-state": "attached"},
+	"dbus-path": "/org/freedesktop/ModemManager1/Modem/0",
+	"generic": {
+		"access-technologies": ["lte"],
+		"current-bands": ["eutran-3", "eutran-20"],
+		"device-identifier": "3f2a9c8d5b1e7f60a4c3d2b1e0f9a8b7c6d5e4f3",
+		"drivers": ["option", "qmi_wwan"],
+		"equipment-identifier": "867698041234567",
+		"hardware-revision": "10000",
+		"manufacturer": "Quectel",
+		"model": "EC25",
+		"plugin": "quectel",
+		"ports": ["cdc-wdm0 (qmi)", "wwan0 (net)"],
+		"power-state": "on",
+		"revision": "EC25EFAR06A06M4G",
+		"signal-quality": {"recent": "yes", "value": "67"},
+		"sim": "/org/freedesktop/ModemManager1/SIM/0",
+		"state": "connected",
+		"state-failed-reason": "--",
+		"unlock-required": "none",
+		"unlock-retries": ["sim-pin (3)", "sim-puk (10)"]
+	}
+}}`
+
+const snapshotSIMJSON = `{"sim": {"dbus-path": "/org/freedesktop/ModemManager1/SIM/0", "properties": {
+	"active": "yes", "eid": "--", "iccid": "89490200001234567890", "imsi": "262011234567890",
+	"operator-code": "26201", "operator-name": "Telekom.de"
+}}}`
+
+const snapshotLocationJSON = `{"modem": {"location": {
+	"3gpp": {"mcc": "262", "mnc": "01", "lac": "FFFE", "cid": "01A2B3C4", "tac": "--"},
+	"gps": {"latitude": "52.520008", "longitude": "13.404954", "altitude": "41.200000", "utc": "--", "nmea": []},
+	"cdma-bs": {"latitude": "--", "longitude": "--"}
+}}}`
+
+const snapshotSignalJSON = `{"modem": {"signal": {
+	"refresh": {"rate": "10"},
+	"threshold": {"rssi": "0", "error-rate": "no"},
+	"lte": {"rsrp": "-95.00", "rsrq": "-11.00", "rssi": "-65.00", "snr": "8.40", "sinr": "12.50", "error-rate": "--"},
+	"gsm": {"rssi": "--", "error-rate": "--"}
+}}}`
+
+const snapshotTimeJSON = `{"modem": {"time": {"network-time": "2024-03-15T11:15:30+01:00", "local": "--"}}}`
+
+func snapshotData(t *testing.T) SnapshotData {
+	t.Helper()
+	mm, err := Parse([]byte(snapshotModemJSON))
+	if err != nil {
+		t.Fatalf("Failed to parse modem: %v", err)
+	}
+
+	var sim SIMInfo
+	var location struct {
+		Modem struct {
+			Location LocationInfo `json:"location"`
+		} `json:"modem"`
+	}
+	var signal struct {
+		Modem struct {
+			Signal SignalInfo `json:"signal"`
+		} `json:"modem"`
+	}
+	var networkTime struct {
+		Modem struct {
+			Time TimeInfo `json:"time"`
+		} `json:"modem"`
+	}
+	var simResponse struct {
+		SIM *SIMInfo `json:"sim"`
+	}
+	simResponse.SIM = &sim
+	for data, v := range map[string]interface{}{
+		snapshotSIMJSON:      &simResponse,
+		snapshotLocationJSON: &location,
+		snapshotSignalJSON:   &signal,
+		snapshotTimeJSON:     &networkTime,
+	} {
+		if err := json.Unmarshal([]byte(data), v); err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", data, err)
+		}
+	}
+
+	return SnapshotData{
+		Modem:    mm,
+		SIM:      &sim,
+		Location: &location.Modem.Location,
+		Signal:   &signal.Modem.Signal,
+		Time:     &networkTime.Modem.Time,
+	}
+}
+
+// TestSnapshotSchema checks the snapshot against the published schema in
+// testdata/snapshot-v1.json. Changing it requires a new SnapshotVersion if a
+// field is removed or changes its meaning.
+func TestSnapshotSchema(t *testing.T) {
+	snapshot := NewSnapshot(snapshotData(t), time.Date(2024, 3, 15, 10, 15, 31, 0, time.UTC))
+
+	got, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal snapshot: %v", err)
+	}
+	expected, err := os.ReadFile("testdata/snapshot-v1.json")
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(got), bytes.TrimSpace(expected)) {
+		t.Errorf("Snapshot differs from testdata/snapshot-v1.json:\n%s", got)
+	}
+
+	var decoded Snapshot
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal snapshot: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, snapshot) {
+		t.Errorf("Round trip changed %+v to %+v", snapshot, decoded)
+	}
+}
+
+func TestSnapshotUnknownValues(t *testing.T) {
+	var mm ModemManager
+	mm.Modem.Generic.State = "failed"
+	mm.Modem.Generic.StateFailedReason = "sim-missing"
+	mm.Modem.Generic.SignalQuality = SignalQuality{Value: "--", Recent: "no"}
+	mm.Modem.ThreeGPP.OperatorName = "--"
+	mm.Normalize()
+
+	snapshot := NewSnapshot(SnapshotData{Modem: &mm, Location: &LocationInfo{}}, time.Time{})
+	if snapshot.Modem.FailedReason == nil || *snapshot.Modem.FailedReason != StateFailedReasonSimMissing {
+		t.Errorf("Expected failed reason sim-missing, got %v", snapshot.Modem.FailedReason)
+	}
+	if snapshot.Network.OperatorName != "" || snapshot.Network.Generation != "" || snapshot.Signal.QualityPercent != nil {
+		t.Errorf("Expected unknown values to be omitted, got %+v", snapshot)
+	}
+	if snapshot.Location != nil || snapshot.SIM != nil || snapshot.Time != nil {
+		t.Errorf("Expected no location, SIM or time, got %+v", snapshot)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to marshal snapshot: %v", err)
+	}
+	if bytes.Contains(data, []byte(`"generation"`)) {
+		t.Errorf("Expected the unknown generation to be omitted, got %s", data)
+	}
+}
+
+func TestModemHandleSnapshot(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-m 0 -J": {Stdout: []byte(snapshotModemJSON)},
+		"-i /org/freedesktop/ModemManager1/SIM/0 -J":                {Stdout: []byte(snapshotSIMJSON)},
+		"-m /org/freedesktop/ModemManager1/Modem/0 --signal-get -J": {Stdout: []byte(snapshotSignalJSON)},
+		"-m /org/freedesktop/ModemManager1/Modem/0 --time -J":       {Stdout: []byte(snapshotTimeJSON)},
+		// --location-get is not answered, as on modems without location support
+	}}
+	client := NewClient(runner)
+
+	snapshot, err := client.TakeSnapshot(context.Background(), "0")
+	if err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+	if snapshot.Version != SnapshotVersion || snapshot.Modem.IMEI != "867698041234567" {
+		t.Errorf("Unexpected snapshot %+v", snapshot)
+	}
+	if snapshot.SIM == nil || snapshot.SIM.ICCID != "89490200001234567890" {
+		t.Errorf("Unexpected SIM %+v", snapshot.SIM)
+	}
+	if snapshot.Signal.LTE == nil || snapshot.Time == nil {
+		t.Errorf("Expected signal and time, got %+v", snapshot)
+	}
+	if snapshot.Location != nil {
+		t.Errorf("Expected no location, got %+v", snapshot.Location)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.TakeSnapshot(ctx, "0"); err == nil {
+		t.Error("Expected an error for a cancelled context")
+	}
+}
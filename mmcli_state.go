@@ -0,0 +1,233 @@
+package mmcli
+
+import "fmt"
+
+// ModemState is the state of a modem as reported by ModemManager. The values
+// match MMModemState, so states can be compared by their order: every state
+// from ModemStateEnabled on means the modem is enabled, every state from
+// ModemStateRegistered on means it is registered to a network.
+type ModemState int
+
+// Modem states
+const (
+	ModemStateFailed        ModemState = -1
+	ModemStateUnknown       ModemState = 0
+	ModemStateInitializing  ModemState = 1
+	ModemStateLocked        ModemState = 2
+	ModemStateDisabled      ModemState = 3
+	ModemStateDisabling     ModemState = 4
+	ModemStateEnabling      ModemState = 5
+	ModemStateEnabled       ModemState = 6
+	ModemStateSearching     ModemState = 7
+	ModemStateRegistered    ModemState = 8
+	ModemStateDisconnecting ModemState = 9
+	ModemStateConnecting    ModemState = 10
+	ModemStateConnected     ModemState = 11
+)
+
+var modemStateNames = map[ModemState]string{
+	ModemStateFailed:        "failed",
+	ModemStateUnknown:       "unknown",
+	ModemStateInitializing:  "initializing",
+	ModemStateLocked:        "locked",
+	ModemStateDisabled:      "disabled",
+	ModemStateDisabling:     "disabling",
+	ModemStateEnabling:      "enabling",
+	ModemStateEnabled:       "enabled",
+	ModemStateSearching:     "searching",
+	ModemStateRegistered:    "registered",
+	ModemStateDisconnecting: "disconnecting",
+	ModemStateConnecting:    "connecting",
+	ModemStateConnected:     "connected",
+}
+
+// ParseModemState parses a state as printed by mmcli, e.g. "registered"
+func ParseModemState(s string) (ModemState, error) {
+	for state, name := range modemStateNames {
+		if name == s {
+			return state, nil
+		}
+	}
+	return ModemStateUnknown, fmt.Errorf("unknown modem state %q", s)
+}
+
+// String returns the state as printed by mmcli
+func (s ModemState) String() string {
+	if name, ok := modemStateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s ModemState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *ModemState) UnmarshalText(text []byte) error {
+	state, err := ParseModemState(string(text))
+	if err != nil {
+		return err
+	}
+	*s = state
+	return nil
+}
+
+// AtLeast reports whether s is the given state or a later one, e.g.
+// s.AtLeast(ModemStateRegistered) for "registered or connected"
+func (s ModemState) AtLeast(state ModemState) bool {
+	return s >= state
+}
+
+// IsEnabled reports whether the modem is enabled
+func (s ModemState) IsEnabled() bool {
+	return s >= ModemStateEnabled
+}
+
+// IsRegistered reports whether the modem is registered to a network
+func (s ModemState) IsRegistered() bool {
+	return s >= ModemStateRegistered
+}
+
+// IsConnected reports whether the modem has an active data connection
+func (s ModemState) IsConnected() bool {
+	return s == ModemStateConnected
+}
+
+// IsTransitional reports whether the modem is changing between stable states
+// (initializing, enabling, disabling, connecting or disconnecting)
+func (s ModemState) IsTransitional() bool {
+	switch s {
+	case ModemStateInitializing, ModemStateEnabling, ModemStateDisabling,
+		ModemStateConnecting, ModemStateDisconnecting:
+		return true
+	}
+	return false
+}
+
+// StateFailedReason tells why a modem is in the failed state. The values
+// match MMModemStateFailedReason.
+type StateFailedReason int
+
+// Reasons for the failed state
+const (
+	StateFailedReasonNone                StateFailedReason = 0
+	StateFailedReasonUnknown             StateFailedReason = 1
+	StateFailedReasonSimMissing          StateFailedReason = 2
+	StateFailedReasonSimError            StateFailedReason = 3
+	StateFailedReasonUnknownCapabilities StateFailedReason = 4
+	StateFailedReasonEsimWithoutProfiles StateFailedReason = 5
+)
+
+var stateFailedReasonNames = map[StateFailedReason]string{
+	StateFailedReasonNone:                "none",
+	StateFailedReasonUnknown:             "unknown",
+	StateFailedReasonSimMissing:          "sim-missing",
+	StateFailedReasonSimError:            "sim-error",
+	StateFailedReasonUnknownCapabilities: "unknown-capabilities",
+	StateFailedReasonEsimWithoutProfiles: "esim-without-profiles",
+}
+
+// ParseStateFailedReason parses a failed reason as printed by mmcli, e.g.
+// "sim-missing". mmcli prints "--" when the modem has not failed.
+func ParseStateFailedReason(s string) (StateFailedReason, error) {
+	if s == "" || s == "--" {
+		return StateFailedReasonNone, nil
+	}
+	for reason, name := range stateFailedReasonNames {
+		if name == s {
+			return reason, nil
+		}
+	}
+	return StateFailedReasonUnknown, fmt.Errorf("unknown state failed reason %q", s)
+}
+
+// String returns the reason as printed by mmcli
+func (r StateFailedReason) String() string {
+	if name, ok := stateFailedReasonNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (r StateFailedReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (r *StateFailedReason) UnmarshalText(text []byte) error {
+	reason, err := ParseStateFailedReason(string(text))
+	if err != nil {
+		return err
+	}
+	*r = reason
+	return nil
+}
+
+// StateChangeReason tells why a modem changed its state. The values match
+// MMModemStateChangeReason.
+type StateChangeReason int
+
+// Reasons for a state change
+const (
+	StateChangeReasonUnknown       StateChangeReason = 0
+	StateChangeReasonUserRequested StateChangeReason = 1
+	StateChangeReasonSuspend       StateChangeReason = 2
+	StateChangeReasonFailure       StateChangeReason = 3
+)
+
+var stateChangeReasonNames = map[StateChangeReason]string{
+	StateChangeReasonUnknown:       "unknown",
+	StateChangeReasonUserRequested: "user-requested",
+	StateChangeReasonSuspend:       "suspend",
+	StateChangeReasonFailure:       "failure",
+}
+
+// ParseStateChangeReason parses a state change reason as printed by mmcli,
+// e.g. "user-requested"
+func ParseStateChangeReason(s string) (StateChangeReason, error) {
+	for reason, name := range stateChangeReasonNames {
+		if name == s {
+			return reason, nil
+		}
+	}
+	return StateChangeReasonUnknown, fmt.Errorf("unknown state change reason %q", s)
+}
+
+// String returns the reason as printed by mmcli
+func (r StateChangeReason) String() string {
+	if name, ok := stateChangeReasonNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (r StateChangeReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (r *StateChangeReason) UnmarshalText(text []byte) error {
+	reason, err := ParseStateChangeReason(string(text))
+	if err != nil {
+		return err
+	}
+	*r = reason
+	return nil
+}
+
+// State returns the modem state, or ModemStateUnknown for a state this
+// package does not know
+func (mm *ModemManager) State() ModemState {
+	state, _ := ParseModemState(mm.Modem.Generic.State)
+	return state
+}
+
+// FailedReason returns why the modem is in the failed state
+func (mm *ModemManager) FailedReason() StateFailedReason {
+	reason, _ := ParseStateFailedReason(mm.Modem.Generic.StateFailedReason)
+	return reason
+}
@@ -0,0 +1,145 @@
+package mmcli
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseModemState(t *testing.T) {
+	for state := ModemStateFailed; state <= ModemStateConnected; state++ {
+		got, err := ParseModemState(state.String())
+		if err != nil {
+			t.Errorf("ParseModemState(%q): %v", state, err)
+			continue
+		}
+		if got != state {
+			t.Errorf("ParseModemState(%q) = %d, expected %d", state, got, state)
+		}
+	}
+
+	if _, err := ParseModemState("sleeping"); err == nil {
+		t.Error("Expected an error for an unknown state")
+	}
+	if ModemState(42).String() != "unknown" {
+		t.Errorf("Expected unknown, got %s", ModemState(42))
+	}
+}
+
+func TestModemStateHelpers(t *testing.T) {
+	tests := []struct {
+		state        ModemState
+		enabled      bool
+		registered   bool
+		transitional bool
+	}{
+		{ModemStateFailed, false, false, false},
+		{ModemStateUnknown, false, false, false},
+		{ModemStateInitializing, false, false, true},
+		{ModemStateLocked, false, false, false},
+		{ModemStateDisabled, false, false, false},
+		{ModemStateDisabling, false, false, true},
+		{ModemStateEnabling, false, false, true},
+		{ModemStateEnabled, true, false, false},
+		{ModemStateSearching, true, false, false},
+		{ModemStateRegistered, true, true, false},
+		{ModemStateDisconnecting, true, true, true},
+		{ModemStateConnecting, true, true, true},
+		{ModemStateConnected, true, true, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.IsEnabled(); got != tt.enabled {
+			t.Errorf("%s: IsEnabled() = %v, expected %v", tt.state, got, tt.enabled)
+		}
+		if got := tt.state.IsRegistered(); got != tt.registered {
+			t.Errorf("%s: IsRegistered() = %v, expected %v", tt.state, got, tt.registered)
+		}
+		if got := tt.state.IsTransitional(); got != tt.transitional {
+			t.Errorf("%s: IsTransitional() = %v, expected %v", tt.state, got, tt.transitional)
+		}
+		if got := tt.state.AtLeast(ModemStateRegistered); got != tt.registered {
+			t.Errorf("%s: AtLeast(registered) = %v, expected %v", tt.state, got, tt.registered)
+		}
+	}
+
+	if !ModemStateConnected.IsConnected() || ModemStateConnecting.IsConnected() {
+		t.Error("Unexpected IsConnected result")
+	}
+}
+
+func TestParseStateFailedReason(t *testing.T) {
+	tests := map[string]StateFailedReason{
+		"--":                    StateFailedReasonNone,
+		"":                      StateFailedReasonNone,
+		"none":                  StateFailedReasonNone,
+		"sim-missing":           StateFailedReasonSimMissing,
+		"sim-error":             StateFailedReasonSimError,
+		"esim-without-profiles": StateFailedReasonEsimWithoutProfiles,
+	}
+
+	for input, want := range tests {
+		got, err := ParseStateFailedReason(input)
+		if err != nil {
+			t.Errorf("ParseStateFailedReason(%q): %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseStateFailedReason(%q) = %s, expected %s", input, got, want)
+		}
+	}
+
+	if _, err := ParseStateFailedReason("broken"); err == nil {
+		t.Error("Expected an error for an unknown reason")
+	}
+}
+
+func TestParseStateChangeReason(t *testing.T) {
+	for reason, name := range stateChangeReasonNames {
+		got, err := ParseStateChangeReason(name)
+		if err != nil || got != reason {
+			t.Errorf("ParseStateChangeReason(%q) = %s, %v, expected %s", name, got, err, reason)
+		}
+	}
+	if _, err := ParseStateChangeReason("broken"); err == nil {
+		t.Error("Expected an error for an unknown reason")
+	}
+}
+
+func TestModemManagerState(t *testing.T) {
+	var mm ModemManager
+	mm.Modem.Generic.State = "failed"
+	mm.Modem.Generic.StateFailedReason = "sim-missing"
+
+	if mm.State() != ModemStateFailed {
+		t.Errorf("Expected failed, got %s", mm.State())
+	}
+	if mm.FailedReason() != StateFailedReasonSimMissing {
+		t.Errorf("Expected sim-missing, got %s", mm.FailedReason())
+	}
+
+	mm.Modem.Generic.State = "something-new"
+	if mm.State() != ModemStateUnknown {
+		t.Errorf("Expected unknown for an unrecognised state, got %s", mm.State())
+	}
+}
+
+func TestModemStateJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		State  ModemState        `json:"state"`
+		Reason StateFailedReason `json:"reason"`
+	}{ModemStateRegistered, StateFailedReasonNone})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if string(data) != `{"state":"registered","reason":"none"}` {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+
+	var state ModemState
+	if err := json.Unmarshal([]byte(`"connecting"`), &state); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if state != ModemStateConnecting {
+		t.Errorf("Expected connecting, got %s", state)
+	}
+}
@@ -0,0 +1,144 @@
+package mmcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamRunner starts long-lived mmcli invocations, such as mmcli -M, whose
+// output is consumed while mmcli keeps running.
+//
+// The returned stream yields mmcli's standard output and reports io.EOF once
+// mmcli exited. Closing the stream stops mmcli if it is still running and
+// returns an error if mmcli failed on its own, a *StreamExitError if it
+// exited with a non-zero status. Cancelling ctx stops mmcli as well.
+type StreamRunner interface {
+	Stream(ctx context.Context, args ...string) (io.ReadCloser, error)
+}
+
+// StreamExitError reports a long-lived mmcli that exited with a non-zero
+// status on its own
+type StreamExitError struct {
+	ExitCode int
+	Stderr   []byte
+}
+
+func (e *StreamExitError) Error() string {
+	if msg := strings.TrimSpace(string(e.Stderr)); msg != "" {
+		return fmt.Sprintf("exit status %d: %s", e.ExitCode, msg)
+	}
+	return fmt.Sprintf("exit status %d", e.ExitCode)
+}
+
+// Stream starts mmcli and returns its standard output while it runs
+func (r ExecRunner) Stream(ctx context.Context, args ...string) (io.ReadCloser, error) {
+	path := r.Path
+	if path == "" {
+		path = "mmcli"
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &execStream{cancel: cancel}
+	s.cmd = exec.CommandContext(ctx, path, args...)
+	s.cmd.Stderr = &s.stderr
+	s.cmd.WaitDelay = waitDelay
+
+	stdout, err := s.cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := s.cmd.Start(); err != nil {
+		cancel()
+		return nil, err
+	}
+	s.stdout = stdout
+	return s, nil
+}
+
+type execStream struct {
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	stdout io.Reader
+	stderr bytes.Buffer
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func (s *execStream) Read(p []byte) (int, error) {
+	return s.stdout.Read(p)
+}
+
+func (s *execStream) Close() error {
+	s.closeOnce.Do(func() {
+		s.cancel()
+		err := s.cmd.Wait()
+
+		// mmcli killed by a signal was stopped by the cancellation above
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Exited() {
+				s.closeErr = &StreamExitError{ExitCode: exitErr.ExitCode(), Stderr: s.stderr.Bytes()}
+			}
+			return
+		}
+		if err != nil {
+			if msg := strings.TrimSpace(s.stderr.String()); msg != "" {
+				err = fmt.Errorf("%w: %s", err, msg)
+			}
+			s.closeErr = err
+		}
+	})
+	return s.closeErr
+}
+
+// stream starts a long-lived mmcli invocation through the client's runner
+func (c *Client) stream(ctx context.Context, args ...string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := c.checkSupported(ctx, args); err != nil {
+		return nil, err
+	}
+
+	runner, ok := c.Runner().(StreamRunner)
+	if !ok {
+		return nil, fmt.Errorf("runner %T cannot run long-lived mmcli invocations: %w", c.Runner(), ErrUnsupported)
+	}
+	return runner.Stream(ctx, args...)
+}
+
+// streamError converts a *StreamExitError into an *Error, as run does for a
+// failed invocation, so that it matches the sentinel errors
+func streamError(args []string, err error) error {
+	var exitErr *StreamExitError
+	if errors.As(err, &exitErr) {
+		return parseError(args, &Result{ExitCode: exitErr.ExitCode, Stderr: exitErr.Stderr})
+	}
+	return err
+}
+
+// streamRestartDelay is how long watchers wait before restarting an mmcli
+// invocation that exited
+var streamRestartDelay = time.Second
+
+// sleep waits for d or until ctx is cancelled, and reports whether it waited
+// the whole duration
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,70 @@
+package mmcli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecRunnerStream(t *testing.T) {
+	stream, err := ExecRunner{Path: "sh"}.Stream(context.Background(), "-c", "echo one; echo two; echo failed >&2; exit 3")
+	if err != nil {
+		t.Skip("sh not available:", err)
+	}
+
+	out, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+	if string(out) != "one\ntwo\n" {
+		t.Errorf("Expected two lines, got %q", out)
+	}
+	err = stream.Close()
+	var exitErr *StreamExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode != 3 || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("Expected the exit status and stderr, got %v", err)
+	}
+}
+
+func TestExecRunnerStreamClose(t *testing.T) {
+	stream, err := ExecRunner{Path: "sh"}.Stream(context.Background(), "-c", "echo ready; exec sleep 10")
+	if err != nil {
+		t.Skip("sh not available:", err)
+	}
+
+	line, err := bufio.NewReader(stream).ReadString('\n')
+	if err != nil || line != "ready\n" {
+		t.Fatalf("Expected a line while mmcli runs, got %q, %v", line, err)
+	}
+
+	start := time.Now()
+	if err := stream.Close(); err != nil {
+		t.Errorf("Expected no error when stopping the stream, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected child to be killed promptly, took %v", elapsed)
+	}
+}
+
+func TestExecRunnerStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	stream, err := ExecRunner{Path: "sh"}.Stream(ctx, "-c", "exec sleep 10")
+	if err != nil {
+		t.Skip("sh not available:", err)
+	}
+	defer stream.Close()
+
+	start := time.Now()
+	if _, err := io.ReadAll(stream); err != nil && !errors.Is(err, io.EOF) {
+		t.Errorf("Expected the stream to end, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected child to be killed promptly, took %v", elapsed)
+	}
+}
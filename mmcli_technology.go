@@ -0,0 +1,262 @@
+package mmcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AccessTechnology is a set of radio access technologies. The bits match
+// MMModemAccessTechnology, so a modem attached to LTE with a 5G NR secondary
+// cell reports AccessTechnologyLTE|AccessTechnology5GNR.
+type AccessTechnology uint32
+
+// Access technologies
+const (
+	AccessTechnologyPOTS       AccessTechnology = 1 << 0
+	AccessTechnologyGSM        AccessTechnology = 1 << 1
+	AccessTechnologyGSMCompact AccessTechnology = 1 << 2
+	AccessTechnologyGPRS       AccessTechnology = 1 << 3
+	AccessTechnologyEDGE       AccessTechnology = 1 << 4
+	AccessTechnologyUMTS       AccessTechnology = 1 << 5
+	AccessTechnologyHSDPA      AccessTechnology = 1 << 6
+	AccessTechnologyHSUPA      AccessTechnology = 1 << 7
+	AccessTechnologyHSPA       AccessTechnology = 1 << 8
+	AccessTechnologyHSPAPlus   AccessTechnology = 1 << 9
+	AccessTechnology1xRTT      AccessTechnology = 1 << 10
+	AccessTechnologyEVDO0      AccessTechnology = 1 << 11
+	AccessTechnologyEVDOA      AccessTechnology = 1 << 12
+	AccessTechnologyEVDOB      AccessTechnology = 1 << 13
+	AccessTechnologyLTE        AccessTechnology = 1 << 14
+	AccessTechnology5GNR       AccessTechnology = 1 << 15
+	AccessTechnologyLTECatM    AccessTechnology = 1 << 16
+	AccessTechnologyLTENBIoT   AccessTechnology = 1 << 17
+)
+
+// accessTechnologyInfo describes a single access technology
+type accessTechnologyInfo struct {
+	tech       AccessTechnology
+	nick       string // as printed by mmcli
+	name       string // human readable
+	generation Generation
+}
+
+// accessTechnologies lists all access technologies in bit order
+var accessTechnologies = []accessTechnologyInfo{
+	{AccessTechnologyPOTS, "pots", "POTS", GenerationUnknown},
+	{AccessTechnologyGSM, "gsm", "GSM", Generation2G},
+	{AccessTechnologyGSMCompact, "gsm-compact", "GSM Compact", Generation2G},
+	{AccessTechnologyGPRS, "gprs", "GPRS", Generation2G},
+	{AccessTechnologyEDGE, "edge", "EDGE", Generation2G},
+	{AccessTechnologyUMTS, "umts", "UMTS", Generation3G},
+	{AccessTechnologyHSDPA, "hsdpa", "HSDPA", Generation3G},
+	{AccessTechnologyHSUPA, "hsupa", "HSUPA", Generation3G},
+	{AccessTechnologyHSPA, "hspa", "HSPA", Generation3G},
+	{AccessTechnologyHSPAPlus, "hspa-plus", "HSPA+", Generation3G},
+	{AccessTechnology1xRTT, "1xrtt", "CDMA2000 1xRTT", Generation3G},
+	{AccessTechnologyEVDO0, "evdo0", "EV-DO Rev. 0", Generation3G},
+	{AccessTechnologyEVDOA, "evdoa", "EV-DO Rev. A", Generation3G},
+	{AccessTechnologyEVDOB, "evdob", "EV-DO Rev. B", Generation3G},
+	{AccessTechnologyLTE, "lte", "LTE", Generation4G},
+	{AccessTechnology5GNR, "5gnr", "5G NR", Generation5GSA},
+	{AccessTechnologyLTECatM, "lte-cat-m", "LTE Cat-M", GenerationLTECatM},
+	{AccessTechnologyLTENBIoT, "lte-nb-iot", "NB-IoT", GenerationNBIoT},
+}
+
+// accessTechnologyAliases holds spellings used by older releases and other tools
+var accessTechnologyAliases = map[string]AccessTechnology{
+	"hspa+":  AccessTechnologyHSPAPlus,
+	"nr5g":   AccessTechnology5GNR,
+	"cat-m":  AccessTechnologyLTECatM,
+	"nb-iot": AccessTechnologyLTENBIoT,
+}
+
+// ParseAccessTechnologies parses access technologies as printed by mmcli,
+// e.g. []string{"lte", "5gnr"}. Entries may also hold several technologies
+// separated by commas. "--" and "unknown" are ignored. Unknown technologies
+// are reported in the error, the returned set holds all known ones.
+func ParseAccessTechnologies(values []string) (AccessTechnology, error) {
+	var set AccessTechnology
+	var unknown []string
+	for _, value := range values {
+		for _, nick := range strings.Split(value, ",") {
+			nick = strings.ToLower(strings.TrimSpace(nick))
+			switch nick {
+			case "", "--", "unknown", "any":
+				continue
+			}
+			tech, ok := lookupAccessTechnology(nick)
+			if !ok {
+				unknown = append(unknown, nick)
+				continue
+			}
+			set |= tech
+		}
+	}
+	if len(unknown) > 0 {
+		return set, fmt.Errorf("unknown access technologies %q", unknown)
+	}
+	return set, nil
+}
+
+func lookupAccessTechnology(nick string) (AccessTechnology, bool) {
+	for _, info := range accessTechnologies {
+		if info.nick == nick {
+			return info.tech, true
+		}
+	}
+	tech, ok := accessTechnologyAliases[nick]
+	return tech, ok
+}
+
+// Has reports whether all technologies in tech are in the set
+func (a AccessTechnology) Has(tech AccessTechnology) bool {
+	return tech != 0 && a&tech == tech
+}
+
+// Technologies returns the single technologies in the set, in bit order
+func (a AccessTechnology) Technologies() []AccessTechnology {
+	var techs []AccessTechnology
+	for _, info := range accessTechnologies {
+		if a&info.tech != 0 {
+			techs = append(techs, info.tech)
+		}
+	}
+	return techs
+}
+
+// Strings returns the technologies in the set as printed by mmcli
+func (a AccessTechnology) Strings() []string {
+	var nicks []string
+	for _, info := range accessTechnologies {
+		if a&info.tech != 0 {
+			nicks = append(nicks, info.nick)
+		}
+	}
+	return nicks
+}
+
+// String returns the set as printed by mmcli, e.g. "lte, 5gnr"
+func (a AccessTechnology) String() string {
+	if a == 0 {
+		return "unknown"
+	}
+	return strings.Join(a.Strings(), ", ")
+}
+
+// Name returns a human readable name for the set, e.g. "LTE + 5G NR"
+func (a AccessTechnology) Name() string {
+	var names []string
+	for _, info := range accessTechnologies {
+		if a&info.tech != 0 {
+			names = append(names, info.name)
+		}
+	}
+	if len(names) == 0 {
+		return "Unknown"
+	}
+	return strings.Join(names, " + ")
+}
+
+// Best returns the most capable single technology in the set, or 0 for an
+// empty set. LTE-M and NB-IoT rank below LTE but above 3G.
+func (a AccessTechnology) Best() AccessTechnology {
+	var best AccessTechnology
+	for _, info := range accessTechnologies {
+		if a&info.tech == 0 {
+			continue
+		}
+		if best == 0 || info.generation.rank() > best.Generation().rank() ||
+			(info.generation == best.Generation() && info.tech > best) {
+			best = info.tech
+		}
+	}
+	return best
+}
+
+// Generation returns the network generation of the set. LTE together with
+// 5G NR means 5G non-standalone, 5G NR alone means 5G standalone.
+func (a AccessTechnology) Generation() Generation {
+	if a.Has(AccessTechnology5GNR) {
+		if a&(AccessTechnologyLTE|AccessTechnologyLTECatM|AccessTechnologyLTENBIoT) != 0 {
+			return Generation5GNSA
+		}
+		return Generation5GSA
+	}
+
+	generation := GenerationUnknown
+	for _, info := range accessTechnologies {
+		if a&info.tech != 0 && info.generation.rank() > generation.rank() {
+			generation = info.generation
+		}
+	}
+	return generation
+}
+
+// Generation is a mobile network generation
+type Generation int
+
+// Network generations
+const (
+	GenerationUnknown Generation = iota
+	Generation2G
+	Generation3G
+	Generation4G
+	Generation5GNSA
+	Generation5GSA
+	// GenerationLTECatM is LTE Cat-M1 (LTE-M), an LTE category for IoT devices
+	GenerationLTECatM
+	// GenerationNBIoT is LTE Cat-NB (NB-IoT), an LTE category for IoT devices
+	GenerationNBIoT
+)
+
+var generationNames = map[Generation]string{
+	GenerationUnknown: "Unknown",
+	Generation2G:      "2G",
+	Generation3G:      "3G",
+	Generation4G:      "4G",
+	Generation5GNSA:   "5G NSA",
+	Generation5GSA:    "5G SA",
+	GenerationLTECatM: "LTE-M",
+	GenerationNBIoT:   "NB-IoT",
+}
+
+// generationRanks orders generations by capability
+var generationRanks = map[Generation]int{
+	GenerationUnknown: 0,
+	Generation2G:      1,
+	Generation3G:      2,
+	GenerationNBIoT:   3,
+	GenerationLTECatM: 4,
+	Generation4G:      5,
+	Generation5GNSA:   6,
+	Generation5GSA:    7,
+}
+
+func (g Generation) rank() int {
+	return generationRanks[g]
+}
+
+// String returns the generation, e.g. "4G" or "5G NSA"
+func (g Generation) String() string {
+	if name, ok := generationNames[g]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// Is5G reports whether g is 5G, standalone or not
+func (g Generation) Is5G() bool {
+	return g == Generation5GNSA || g == Generation5GSA
+}
+
+// IsIoT reports whether g is one of the LTE categories for IoT devices
+func (g Generation) IsIoT() bool {
+	return g == GenerationLTECatM || g == GenerationNBIoT
+}
+
+// AccessTechnologies returns the access technologies the modem currently uses.
+// Technologies this package does not know are ignored.
+func (mm *ModemManager) AccessTechnologies() AccessTechnology {
+	set, _ := ParseAccessTechnologies(mm.Modem.Generic.AccessTechnologies)
+	return set
+}
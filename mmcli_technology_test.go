@@ -0,0 +1,132 @@
+package mmcli
+
+import "testing"
+
+func TestParseAccessTechnologies(t *testing.T) {
+	tests := []struct {
+		input []string
+		want  AccessTechnology
+	}{
+		{[]string{"lte"}, AccessTechnologyLTE},
+		{[]string{"lte", "5gnr"}, AccessTechnologyLTE | AccessTechnology5GNR},
+		{[]string{"lte, 5gnr"}, AccessTechnologyLTE | AccessTechnology5GNR},
+		{[]string{"hspa+"}, AccessTechnologyHSPAPlus},
+		{[]string{"hspa-plus"}, AccessTechnologyHSPAPlus},
+		{[]string{"lte-cat-m"}, AccessTechnologyLTECatM},
+		{[]string{"LTE-NB-IOT"}, AccessTechnologyLTENBIoT},
+		{[]string{"--"}, 0},
+		{nil, 0},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAccessTechnologies(tt.input)
+		if err != nil {
+			t.Errorf("ParseAccessTechnologies(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAccessTechnologies(%q) = %s, expected %s", tt.input, got, tt.want)
+		}
+	}
+
+	got, err := ParseAccessTechnologies([]string{"lte", "6g"})
+	if err == nil {
+		t.Error("Expected an error for an unknown technology")
+	}
+	if got != AccessTechnologyLTE {
+		t.Errorf("Expected the known technologies to be kept, got %s", got)
+	}
+}
+
+func TestAccessTechnologyGeneration(t *testing.T) {
+	tests := []struct {
+		tech AccessTechnology
+		want string
+	}{
+		{AccessTechnologyGSM | AccessTechnologyEDGE, "2G"},
+		{AccessTechnologyHSDPA, "3G"},
+		{AccessTechnologyHSUPA, "3G"},
+		{AccessTechnology1xRTT, "3G"},
+		{AccessTechnologyEVDOA, "3G"},
+		{AccessTechnologyLTE, "4G"},
+		{AccessTechnologyLTE | AccessTechnology5GNR, "5G NSA"},
+		{AccessTechnology5GNR, "5G SA"},
+		{AccessTechnologyLTECatM, "LTE-M"},
+		{AccessTechnologyLTENBIoT, "NB-IoT"},
+		{AccessTechnologyLTECatM | AccessTechnologyLTENBIoT, "LTE-M"},
+		{AccessTechnologyPOTS, "Unknown"},
+		{0, "Unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.tech.Generation().String(); got != tt.want {
+			t.Errorf("%s: Generation() = %s, expected %s", tt.tech, got, tt.want)
+		}
+	}
+
+	if !Generation5GNSA.Is5G() || Generation4G.Is5G() {
+		t.Error("Unexpected Is5G result")
+	}
+	if !GenerationNBIoT.IsIoT() || Generation4G.IsIoT() {
+		t.Error("Unexpected IsIoT result")
+	}
+}
+
+func TestAccessTechnologyBest(t *testing.T) {
+	tests := []struct {
+		tech AccessTechnology
+		want AccessTechnology
+	}{
+		{AccessTechnologyLTE | AccessTechnology5GNR, AccessTechnology5GNR},
+		{AccessTechnologyUMTS | AccessTechnologyHSPAPlus, AccessTechnologyHSPAPlus},
+		{AccessTechnologyLTENBIoT | AccessTechnologyLTECatM, AccessTechnologyLTECatM},
+		{AccessTechnologyLTECatM | AccessTechnologyEDGE, AccessTechnologyLTECatM},
+		{AccessTechnologyLTE | AccessTechnologyLTECatM, AccessTechnologyLTE},
+		{0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.tech.Best(); got != tt.want {
+			t.Errorf("%s: Best() = %s, expected %s", tt.tech, got, tt.want)
+		}
+	}
+}
+
+func TestAccessTechnologyStrings(t *testing.T) {
+	tech := AccessTechnologyLTE | AccessTechnology5GNR
+
+	if tech.String() != "lte, 5gnr" {
+		t.Errorf("Expected lte, 5gnr, got %s", tech)
+	}
+	if tech.Name() != "LTE + 5G NR" {
+		t.Errorf("Expected LTE + 5G NR, got %s", tech.Name())
+	}
+	if AccessTechnology(0).String() != "unknown" {
+		t.Errorf("Expected unknown, got %s", AccessTechnology(0))
+	}
+	if !tech.Has(AccessTechnologyLTE) || tech.Has(AccessTechnologyLTE|AccessTechnologyUMTS) {
+		t.Error("Unexpected Has result")
+	}
+	if techs := tech.Technologies(); len(techs) != 2 || techs[0] != AccessTechnologyLTE {
+		t.Errorf("Unexpected technologies %v", techs)
+	}
+}
+
+func TestGetCurrentAccessTechnology(t *testing.T) {
+	tests := map[string][]string{
+		"4G":     {"lte"},
+		"5G NSA": {"lte", "5gnr"},
+		"LTE-M":  {"lte-cat-m"},
+		"NB-IoT": {"lte-nb-iot"},
+		"3G":     {"hsdpa"},
+		"2G":     {"gprs"},
+	}
+
+	for want, techs := range tests {
+		var mm ModemManager
+		mm.Modem.Generic.AccessTechnologies = techs
+		if got := mm.GetCurrentAccessTechnology(); got != want {
+			t.Errorf("%v: expected %s, got %s", techs, want, got)
+		}
+	}
+}
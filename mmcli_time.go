@@ -1,9 +1,8 @@
 package mmcli
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os/exec"
 	"time"
 )
 
@@ -15,7 +14,17 @@ type TimeInfo struct {
 
 // GetNetworkTime returns the current network time
 func GetNetworkTime(modemID string) (*TimeInfo, error) {
-	out, err := exec.Command("mmcli", "-m", modemID, "--time", "-J").Output()
+	return GetNetworkTimeContext(context.Background(), modemID)
+}
+
+// GetNetworkTimeContext is like GetNetworkTime but uses ctx to bound the mmcli invocation
+func GetNetworkTimeContext(ctx context.Context, modemID string) (*TimeInfo, error) {
+	return DefaultClient.GetNetworkTime(ctx, modemID)
+}
+
+// GetNetworkTime returns the current network time
+func (c *Client) GetNetworkTime(ctx context.Context, modemID string) (*TimeInfo, error) {
+	out, err := c.run(ctx, "-m", modemID, "--time", c.output())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get network time: %w", err)
 	}
@@ -26,7 +35,7 @@ func GetNetworkTime(modemID string) (*TimeInfo, error) {
 		} `json:"modem"`
 	}
 
-	if err := json.Unmarshal(out, &response); err != nil {
+	if err := c.unmarshal(out, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse network time: %w", err)
 	}
 
@@ -35,14 +44,29 @@ func GetNetworkTime(modemID string) (*TimeInfo, error) {
 
 // GetNetworkTimeAsTime returns the current network time as a time.Time object
 func GetNetworkTimeAsTime(modemID string) (time.Time, error) {
-	timeInfo, err := GetNetworkTime(modemID)
+	return GetNetworkTimeAsTimeContext(context.Background(), modemID)
+}
+
+// GetNetworkTimeAsTimeContext is like GetNetworkTimeAsTime but uses ctx to bound the mmcli invocation
+func GetNetworkTimeAsTimeContext(ctx context.Context, modemID string) (time.Time, error) {
+	return DefaultClient.GetNetworkTimeAsTime(ctx, modemID)
+}
+
+// GetNetworkTimeAsTime returns the current network time as a time.Time object
+func (c *Client) GetNetworkTimeAsTime(ctx context.Context, modemID string) (time.Time, error) {
+	timeInfo, err := c.GetNetworkTime(ctx, modemID)
 	if err != nil {
 		return time.Time{}, err
 	}
 
+	return timeInfo.Time()
+}
+
+// Time parses the network time as a time.Time object
+func (ti *TimeInfo) Time() (time.Time, error) {
 	// Parse the network time string
 	// The format is expected to be ISO 8601, e.g. "2023-02-27T13:45:30+01:00"
-	t, err := time.Parse(time.RFC3339, timeInfo.NetworkTime)
+	t, err := time.Parse(time.RFC3339, ti.NetworkTime)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("failed to parse network time string: %w", err)
 	}
@@ -0,0 +1,276 @@
+package mmcli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TranscriptEntry is a single recorded mmcli invocation. Transcripts are
+// stored as JSON lines, one entry per line, with the duration in nanoseconds.
+// ErrorKind is "deadline" or "canceled" for invocations stopped by their
+// context, so that replaying them returns the matching context error.
+type TranscriptEntry struct {
+	Args      []string      `json:"args"`
+	Stdout    string        `json:"stdout,omitempty"`
+	Stderr    string        `json:"stderr,omitempty"`
+	ExitCode  int           `json:"exit-code"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+	ErrorKind string        `json:"error-kind,omitempty"`
+}
+
+// Error kinds recorded in TranscriptEntry.ErrorKind
+const (
+	errorKindDeadline = "deadline"
+	errorKindCanceled = "canceled"
+)
+
+// setError records err and its kind in the entry
+func (e *TranscriptEntry) setError(err error) {
+	if err == nil {
+		return
+	}
+	e.Error = err.Error()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		e.ErrorKind = errorKindDeadline
+	case errors.Is(err, context.Canceled):
+		e.ErrorKind = errorKindCanceled
+	}
+}
+
+// replayedError is a recorded error, which unwraps to the context error of
+// its kind
+type replayedError struct {
+	msg string
+	err error
+}
+
+func (e *replayedError) Error() string { return e.msg }
+func (e *replayedError) Unwrap() error { return e.err }
+
+// replayError returns the recorded error of the entry
+func (e TranscriptEntry) replayError() error {
+	switch e.ErrorKind {
+	case errorKindDeadline:
+		return &replayedError{msg: e.Error, err: context.DeadlineExceeded}
+	case errorKindCanceled:
+		return &replayedError{msg: e.Error, err: context.Canceled}
+	}
+	return errors.New(e.Error)
+}
+
+// RecordingRunner wraps another runner and writes every invocation to a transcript.
+//
+// Arguments are recorded with secrets redacted as by RedactArgs. Output is
+// recorded unchanged and may contain SMS texts, phone numbers and SIM
+// identifiers, so review transcripts before sharing them.
+type RecordingRunner struct {
+	runner Runner
+	mu     sync.Mutex
+	enc    *json.Encoder
+}
+
+// NewRecordingRunner returns a RecordingRunner passing invocations to runner
+// (ExecRunner if nil) and writing the transcript to w
+func NewRecordingRunner(runner Runner, w io.Writer) *RecordingRunner {
+	if runner == nil {
+		runner = ExecRunner{}
+	}
+	return &RecordingRunner{runner: runner, enc: json.NewEncoder(w)}
+}
+
+// Run runs mmcli through the wrapped runner and records the invocation.
+// Failing to write the transcript does not fail the invocation.
+func (r *RecordingRunner) Run(ctx context.Context, args ...string) (*Result, error) {
+	start := time.Now()
+	res, err := r.runner.Run(ctx, args...)
+
+	entry := TranscriptEntry{
+		Args:     RedactArgs(args),
+		Duration: time.Since(start),
+	}
+	if res != nil {
+		entry.Stdout = string(res.Stdout)
+		entry.Stderr = string(res.Stderr)
+		entry.ExitCode = res.ExitCode
+	}
+	entry.setError(err)
+	r.record(entry)
+
+	return res, err
+}
+
+// Stream starts a long-lived mmcli through the wrapped runner, which must
+// implement StreamRunner. The invocation and the output read from it are
+// recorded once the stream is closed.
+func (r *RecordingRunner) Stream(ctx context.Context, args ...string) (io.ReadCloser, error) {
+	runner, ok := r.runner.(StreamRunner)
+	if !ok {
+		return nil, fmt.Errorf("runner %T cannot run long-lived mmcli invocations: %w", r.runner, ErrUnsupported)
+	}
+
+	start := time.Now()
+	stream, err := runner.Stream(ctx, args...)
+	if err != nil {
+		entry := TranscriptEntry{Args: RedactArgs(args), Duration: time.Since(start)}
+		entry.setError(err)
+		r.record(entry)
+		return nil, err
+	}
+	s := &recordingStream{stream: stream, recorder: r, args: args, start: start}
+	s.Reader = io.TeeReader(stream, &s.stdout)
+	return s, nil
+}
+
+// record writes an entry to the transcript, ignoring write failures
+func (r *RecordingRunner) record(entry TranscriptEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.enc.Encode(entry)
+}
+
+// recordingStream records a streamed invocation when it is closed
+type recordingStream struct {
+	io.Reader
+	stream   io.ReadCloser
+	recorder *RecordingRunner
+	args     []string
+	start    time.Time
+	stdout   bytes.Buffer
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func (s *recordingStream) Close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.stream.Close()
+		entry := TranscriptEntry{Args: RedactArgs(s.args), Stdout: s.stdout.String(), Duration: time.Since(s.start)}
+		entry.setError(s.closeErr)
+		s.recorder.record(entry)
+	})
+	return s.closeErr
+}
+
+// ReadTranscript reads a transcript written by a RecordingRunner
+func ReadTranscript(rd io.Reader) ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry TranscriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse transcript line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ErrNotRecorded is returned by ReplayRunner for invocations missing from the transcript
+var ErrNotRecorded = errors.New("invocation not recorded")
+
+// ReplayRunner serves the invocations of a transcript back without running mmcli.
+//
+// Each invocation is answered by the first unused entry with the same
+// arguments, so repeated invocations get their recorded results in order.
+// The --timeout option is ignored when comparing arguments, since it depends
+// on the context deadline at the time of recording, and secrets are compared
+// in their redacted form.
+type ReplayRunner struct {
+	mu      sync.Mutex
+	entries []TranscriptEntry
+	used    []bool
+}
+
+// NewReplayRunner returns a ReplayRunner serving the given entries
+func NewReplayRunner(entries []TranscriptEntry) *ReplayRunner {
+	return &ReplayRunner{entries: entries, used: make([]bool, len(entries))}
+}
+
+// LoadReplayRunner returns a ReplayRunner serving the transcript file at path
+func LoadReplayRunner(path string) (*ReplayRunner, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer f.Close()
+
+	entries, err := ReadTranscript(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayRunner(entries), nil
+}
+
+// Run returns the recorded result for the invocation
+func (r *ReplayRunner) Run(ctx context.Context, args ...string) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.Join(RedactArgs(stripTimeout(args)), "\x00")
+	for i, entry := range r.entries {
+		if r.used[i] || strings.Join(RedactArgs(stripTimeout(entry.Args)), "\x00") != key {
+			continue
+		}
+		r.used[i] = true
+
+		if entry.Error != "" {
+			return nil, entry.replayError()
+		}
+		return &Result{
+			Stdout:   []byte(entry.Stdout),
+			Stderr:   []byte(entry.Stderr),
+			ExitCode: entry.ExitCode,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("mmcli %s: %w", strings.Join(args, " "), ErrNotRecorded)
+}
+
+// Remaining returns the entries that have not been replayed yet
+func (r *ReplayRunner) Remaining() []TranscriptEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var remaining []TranscriptEntry
+	for i, entry := range r.entries {
+		if !r.used[i] {
+			remaining = append(remaining, entry)
+		}
+	}
+	return remaining
+}
+
+// stripTimeout removes the --timeout option from mmcli arguments
+func stripTimeout(args []string) []string {
+	out := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--timeout=") {
+			out = append(out, arg)
+		}
+	}
+	return out
+}
@@ -0,0 +1,193 @@
+package mmcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-J -L":                    {Stdout: []byte(`{"modem-list": ["/org/freedesktop/ModemManager1/Modem/0"]}`)},
+		"-m 0 -J":                  {Stdout: []byte(`{"modem": {"generic": {"state": "connected"}}}`)},
+		"-m 0 --simple-disconnect": {Stderr: []byte("error: couldn't find modem"), ExitCode: 1},
+	}}
+
+	var transcript bytes.Buffer
+	recording := NewClient(NewRecordingRunner(runner, &transcript))
+	ctx := context.Background()
+
+	if _, err := recording.GetFirstModemID(ctx); err != nil {
+		t.Fatalf("Failed to get first modem ID: %v", err)
+	}
+	if _, err := recording.GetModemDetails(ctx, "0"); err != nil {
+		t.Fatalf("Failed to get modem details: %v", err)
+	}
+	if err := recording.Disconnect(ctx, "0"); err == nil {
+		t.Fatal("Expected disconnect to fail")
+	}
+
+	entries, err := ReadTranscript(&transcript)
+	if err != nil {
+		t.Fatalf("Failed to read transcript: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if !reflect.DeepEqual(entries[2].Args, []string{"-m", "0", "--simple-disconnect"}) ||
+		entries[2].ExitCode != 1 || entries[2].Stderr != "error: couldn't find modem" {
+		t.Errorf("Unexpected entry %+v", entries[2])
+	}
+
+	replay := NewReplayRunner(entries)
+	client := NewClient(replay)
+
+	mm, err := client.GetModemDetails(ctx, "0")
+	if err != nil {
+		t.Fatalf("Failed to replay modem details: %v", err)
+	}
+	if !mm.IsConnected() {
+		t.Error("Expected modem to be connected")
+	}
+	if err := client.Disconnect(ctx, "0"); !errors.Is(err, ErrModemNotFound) {
+		t.Errorf("Expected ErrModemNotFound, got %v", err)
+	}
+
+	// each entry is served once
+	if _, err := client.GetModemDetails(ctx, "0"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Expected ErrNotRecorded, got %v", err)
+	}
+
+	remaining := replay.Remaining()
+	if len(remaining) != 1 || !reflect.DeepEqual(remaining[0].Args, []string{"-J", "-L"}) {
+		t.Errorf("Expected only the modem list to remain, got %+v", remaining)
+	}
+}
+
+func TestRecordRedactsSecrets(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		`-m 0 --simple-connect="apn=internet,password=s3cr3t"`: {},
+	}}
+
+	var transcript bytes.Buffer
+	ctx := context.Background()
+	settings := ConnectSettings{APN: "internet", Password: "s3cr3t"}
+	if err := NewClient(NewRecordingRunner(runner, &transcript)).Connect(ctx, "0", settings); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if strings.Contains(transcript.String(), "s3cr3t") {
+		t.Errorf("Expected the password to be redacted, got %s", transcript.String())
+	}
+
+	entries, err := ReadTranscript(&transcript)
+	if err != nil {
+		t.Fatalf("Failed to read transcript: %v", err)
+	}
+	if err := NewClient(NewReplayRunner(entries)).Connect(ctx, "0", settings); err != nil {
+		t.Errorf("Failed to replay a redacted invocation: %v", err)
+	}
+}
+
+func TestReplayContextErrors(t *testing.T) {
+	runner := RunnerFunc(func(ctx context.Context, args ...string) (*Result, error) {
+		if strings.Join(args, " ") == "-J -L" {
+			return nil, context.Canceled
+		}
+		return nil, context.DeadlineExceeded
+	})
+
+	var transcript bytes.Buffer
+	recording := NewClient(NewRecordingRunner(runner, &transcript))
+	ctx := context.Background()
+	recording.ListModems(ctx)
+	recording.GetModemDetails(ctx, "0")
+
+	entries, err := ReadTranscript(&transcript)
+	if err != nil {
+		t.Fatalf("Failed to read transcript: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ErrorKind != "canceled" || entries[1].ErrorKind != "deadline" {
+		t.Fatalf("Unexpected entries %+v", entries)
+	}
+
+	client := NewClient(NewReplayRunner(entries))
+	if _, err := client.ListModems(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	_, err = client.GetModemDetails(ctx, "0")
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestRecordStream(t *testing.T) {
+	runner := &streamingRunner{
+		scriptedRunner: scriptedRunner{responses: map[string]*Result{}},
+		outputs:        []string{"(+) /org/freedesktop/ModemManager1/Modem/0 [Quectel] EC25\n", ""},
+	}
+
+	var transcript bytes.Buffer
+	recording := NewRecordingRunner(runner, &transcript)
+	stream, err := recording.Stream(context.Background(), "-M")
+	if err != nil {
+		t.Fatalf("Failed to start stream: %v", err)
+	}
+	if _, err := io.ReadAll(stream); err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Failed to close stream: %v", err)
+	}
+
+	entries, err := ReadTranscript(&transcript)
+	if err != nil {
+		t.Fatalf("Failed to read transcript: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Args[0] != "-M" || !strings.HasPrefix(entries[0].Stdout, "(+) ") {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+
+	if _, err := NewRecordingRunner(&scriptedRunner{}, &transcript).Stream(context.Background(), "-M"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for a runner without streaming support, got %v", err)
+	}
+}
+
+func TestReplayTranscript(t *testing.T) {
+	replay, err := LoadReplayRunner("testdata/sim-missing.jsonl")
+	if err != nil {
+		t.Fatalf("Failed to load transcript: %v", err)
+	}
+	client := NewClient(replay)
+
+	// the recording used a different deadline, which must not matter
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id, err := client.GetFirstModemID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get first modem ID: %v", err)
+	}
+
+	mm, err := client.GetModemDetails(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to get modem details: %v", err)
+	}
+	if mm.Modem.Generic.State != "failed" || mm.Modem.Generic.StateFailedReason != "sim-missing" {
+		t.Errorf("Expected failed state with reason sim-missing, got %s (%s)",
+			mm.Modem.Generic.State, mm.Modem.Generic.StateFailedReason)
+	}
+
+	err = client.Connect(ctx, id, ConnectSettings{APN: "internet.telekom"})
+	if !errors.Is(err, ErrSimNotInserted) {
+		t.Errorf("Expected ErrSimNotInserted, got %v", err)
+	}
+
+	if remaining := replay.Remaining(); len(remaining) != 0 {
+		t.Errorf("Expected the whole transcript to be replayed, %d entries left", len(remaining))
+	}
+}
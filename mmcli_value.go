@@ -0,0 +1,329 @@
+package mmcli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unset is what mmcli prints for values that are absent or not known
+const Unset = "--"
+
+// IsSet reports whether mmcli printed an actual value, i.e. neither "--" nor nothing
+func IsSet(s string) bool {
+	s = strings.TrimSpace(s)
+	return s != "" && s != Unset
+}
+
+// The helpers below decode the strings mmcli prints into typed values. They
+// return nil for "--" and for values that cannot be parsed.
+
+func optionalInt(s string) *int {
+	if !IsSet(s) {
+		return nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+func optionalHex(s string) *uint64 {
+	if !IsSet(s) {
+		return nil
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(s), 16, 64)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+func optionalFloat(s string) *float64 {
+	if !IsSet(s) {
+		return nil
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+func optionalTime(s string) *time.Time {
+	if !IsSet(s) {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(s))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// isYes decodes the yes/no flags mmcli prints
+func isYes(s string) bool {
+	switch strings.TrimSpace(s) {
+	case "yes", "true":
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes the signal quality and fills the typed fields
+func (s *SignalQuality) UnmarshalJSON(data []byte) error {
+	type plain SignalQuality
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	s.normalize()
+	return nil
+}
+
+func (s *SignalQuality) normalize() {
+	s.Percent = optionalInt(s.Value)
+	s.IsRecent = isYes(s.Recent)
+}
+
+// UnmarshalJSON decodes the generic modem information and fills the typed fields
+func (g *ModemGenericInfo) UnmarshalJSON(data []byte) error {
+	type plain ModemGenericInfo
+	if err := json.Unmarshal(data, (*plain)(g)); err != nil {
+		return err
+	}
+	g.normalize()
+	return nil
+}
+
+func (g *ModemGenericInfo) normalize() {
+	g.SignalQuality.normalize()
+	g.MaxActiveBearersCount = optionalInt(g.MaxActiveBearers)
+	g.MaxActiveMultiplexedBearersCount = optionalInt(g.MaxActiveMultiplexedBearers)
+	g.MaxBearersCount = optionalInt(g.MaxBearers)
+	g.PrimarySIMSlotNumber = optionalInt(g.PrimarySIMSlot)
+	if g.PrimarySIMSlotNumber != nil && *g.PrimarySIMSlotNumber == 0 {
+		// ModemManager reports slot 0 for modems without multiple SIM slots
+		g.PrimarySIMSlotNumber = nil
+	}
+}
+
+// UnmarshalJSON decodes the SIM properties and fills the typed fields
+func (p *SIMProperties) UnmarshalJSON(data []byte) error {
+	type plain SIMProperties
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+	p.normalize()
+	return nil
+}
+
+func (p *SIMProperties) normalize() {
+	p.IsActive = isYes(p.Active)
+}
+
+// UnmarshalJSON decodes the location status and fills the typed fields
+func (s *LocationStatus) UnmarshalJSON(data []byte) error {
+	type plain LocationStatus
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	s.normalize()
+	return nil
+}
+
+func (s *LocationStatus) normalize() {
+	s.SignalsEnabled = isYes(s.Signals)
+	s.GPS.RefreshRateSeconds = optionalInt(strings.TrimSuffix(s.GPS.RefreshRate, " seconds"))
+}
+
+// UnmarshalJSON decodes the 3GPP location and fills the typed fields
+func (l *ThreeGPPLocation) UnmarshalJSON(data []byte) error {
+	type plain ThreeGPPLocation
+	if err := json.Unmarshal(data, (*plain)(l)); err != nil {
+		return err
+	}
+	l.normalize()
+	return nil
+}
+
+func (l *ThreeGPPLocation) normalize() {
+	l.LACNumber = optionalHex(l.LAC)
+	l.CIDNumber = optionalHex(l.CID)
+	l.TACNumber = optionalHex(l.TAC)
+}
+
+// UnmarshalJSON decodes the GPS location and fills the typed fields
+func (l *GPSLocation) UnmarshalJSON(data []byte) error {
+	type plain GPSLocation
+	if err := json.Unmarshal(data, (*plain)(l)); err != nil {
+		return err
+	}
+	l.normalize()
+	return nil
+}
+
+func (l *GPSLocation) normalize() {
+	l.LatitudeDegrees = optionalFloat(l.Latitude)
+	l.LongitudeDegrees = optionalFloat(l.Longitude)
+	l.AltitudeMeters = optionalFloat(l.Altitude)
+}
+
+// UnmarshalJSON decodes the CDMA base station location and fills the typed fields
+func (l *CDMALocation) UnmarshalJSON(data []byte) error {
+	type plain CDMALocation
+	if err := json.Unmarshal(data, (*plain)(l)); err != nil {
+		return err
+	}
+	l.normalize()
+	return nil
+}
+
+func (l *CDMALocation) normalize() {
+	l.LatitudeDegrees = optionalFloat(l.Latitude)
+	l.LongitudeDegrees = optionalFloat(l.Longitude)
+}
+
+// UnmarshalJSON decodes the SMS properties and fills the typed fields. The
+// class, delivery report request and data are accepted both typed and as the
+// strings mmcli prints, where data is hex encoded and "--" means unset.
+func (p *SMSProperties) UnmarshalJSON(data []byte) error {
+	type plain SMSProperties
+	var raw struct {
+		*plain
+		Class             json.RawMessage `json:"class"`
+		DeliveryReportReq json.RawMessage `json:"delivery-report-request"`
+		Data              json.RawMessage `json:"data"`
+	}
+	raw.plain = (*plain)(p)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var err error
+	if p.Class, err = decodeClass(raw.Class); err != nil {
+		return err
+	}
+	if p.DeliveryReportReq, err = decodeFlag(raw.DeliveryReportReq); err != nil {
+		return err
+	}
+	if p.Data, err = decodeData(raw.Data); err != nil {
+		return err
+	}
+	p.normalize()
+	return nil
+}
+
+// MarshalJSON encodes the SMS properties with the data hex encoded, the way
+// mmcli prints it
+func (p SMSProperties) MarshalJSON() ([]byte, error) {
+	type plain SMSProperties
+	return json.Marshal(struct {
+		plain
+		Data string `json:"data,omitempty"`
+	}{plain(p), strings.ToUpper(hex.EncodeToString(p.Data))})
+}
+
+func (p *SMSProperties) normalize() {
+	p.ValidityMinutes = optionalInt(p.Validity)
+	p.Time = optionalTime(p.Timestamp)
+	p.DischargeTime = optionalTime(p.DischargeTimestamp)
+}
+
+// rawString returns the string in a JSON value, and ok=false if it is not a string
+func rawString(raw json.RawMessage) (s string, ok bool) {
+	if len(raw) == 0 || raw[0] != '"' {
+		return "", false
+	}
+	err := json.Unmarshal(raw, &s)
+	return s, err == nil
+}
+
+func decodeClass(raw json.RawMessage) (int, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+	if s, ok := rawString(raw); ok {
+		if !IsSet(s) {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return 0, fmt.Errorf("invalid SMS class %q", s)
+		}
+		return n, nil
+	}
+	var n int
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return 0, fmt.Errorf("invalid SMS class %s", raw)
+	}
+	return n, nil
+}
+
+func decodeFlag(raw json.RawMessage) (bool, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return false, nil
+	}
+	if s, ok := rawString(raw); ok {
+		return isYes(s), nil
+	}
+	var b bool
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return false, fmt.Errorf("invalid flag %s", raw)
+	}
+	return b, nil
+}
+
+func decodeData(raw json.RawMessage) ([]byte, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	s, ok := rawString(raw)
+	if !ok {
+		return nil, fmt.Errorf("invalid SMS data %s", raw)
+	}
+	if !IsSet(s) {
+		return nil, nil
+	}
+	data, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(s), ":", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMS data %q: %w", s, err)
+	}
+	return data, nil
+}
+
+// Normalize fills the typed fields from the raw strings mmcli printed.
+// Results returned by this package are already normalized; call it after
+// filling or changing the raw fields yourself.
+func (mm *ModemManager) Normalize() {
+	mm.Modem.Generic.normalize()
+	if mm.Modem.Location != nil {
+		mm.Modem.Location.Status.Normalize()
+		mm.Modem.Location.Info.Normalize()
+	}
+}
+
+// Normalize fills the typed fields from the raw strings mmcli printed
+func (s *SIMInfo) Normalize() {
+	s.Properties.normalize()
+}
+
+// Normalize fills the typed fields from the raw strings mmcli printed
+func (s *LocationStatus) Normalize() {
+	s.normalize()
+}
+
+// Normalize fills the typed fields from the raw strings mmcli printed
+func (l *LocationInfo) Normalize() {
+	l.ThreeGPP.normalize()
+	l.GPS.normalize()
+	l.CDMABS.normalize()
+}
+
+// Normalize fills the typed fields from the raw strings mmcli printed
+func (s *SMSInfo) Normalize() {
+	s.Properties.normalize()
+}
@@ -0,0 +1,203 @@
+package mmcli
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIsSet(t *testing.T) {
+	for s, want := range map[string]bool{"--": false, "": false, " -- ": false, "0": true, "no": true} {
+		if got := IsSet(s); got != want {
+			t.Errorf("IsSet(%q) = %v, expected %v", s, got, want)
+		}
+	}
+}
+
+func TestModemValues(t *testing.T) {
+	mm, err := Parse([]byte(`{"modem": {"generic": {"signal-quality": {"recent": "yes", "value": "78"}}}}`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	signal := mm.Modem.Generic.SignalQuality
+	if signal.Percent == nil || *signal.Percent != 78 || !signal.IsRecent {
+		t.Errorf("Unexpected signal quality %+v", signal)
+	}
+	if signal.Value != "78" || signal.Recent != "yes" {
+		t.Errorf("Expected the raw strings to be kept, got %+v", signal)
+	}
+
+	mm, err = Parse([]byte(`{"modem": {"generic": {"signal-quality": {"recent": "no", "value": "--"}}}}`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if signal := mm.Modem.Generic.SignalQuality; signal.Percent != nil || signal.IsRecent {
+		t.Errorf("Expected unknown signal quality, got %+v", signal)
+	}
+}
+
+func TestBearerAndSlotValues(t *testing.T) {
+	mm, err := Parse([]byte(`{"modem": {"generic": {"max-bearers": "3", "max-active-bearers": "1",
+		"max-active-multiplexed-bearers": "--", "primary-sim-slot": "2"}}}`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	generic := mm.Modem.Generic
+	if generic.MaxBearersCount == nil || *generic.MaxBearersCount != 3 ||
+		generic.MaxActiveBearersCount == nil || *generic.MaxActiveBearersCount != 1 {
+		t.Errorf("Unexpected bearer limits %v, %v", generic.MaxBearersCount, generic.MaxActiveBearersCount)
+	}
+	if generic.MaxActiveMultiplexedBearersCount != nil {
+		t.Errorf("Expected no multiplexed bearer limit for --, got %d", *generic.MaxActiveMultiplexedBearersCount)
+	}
+	if generic.PrimarySIMSlotNumber == nil || *generic.PrimarySIMSlotNumber != 2 {
+		t.Errorf("Expected primary SIM slot 2, got %v", generic.PrimarySIMSlotNumber)
+	}
+
+	mm, err = Parse([]byte(`{"modem": {"generic": {"primary-sim-slot": "0", "max-bearers": ""}}}`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if mm.Modem.Generic.PrimarySIMSlotNumber != nil || mm.Modem.Generic.MaxBearersCount != nil {
+		t.Errorf("Expected no slot on a single-SIM modem and no empty limit, got %+v", mm.Modem.Generic)
+	}
+
+	mm.Modem.Generic.MaxBearers = "4"
+	mm.Normalize()
+	if mm.Modem.Generic.MaxBearersCount == nil || *mm.Modem.Generic.MaxBearersCount != 4 {
+		t.Errorf("Expected Normalize to decode the changed limit, got %v", mm.Modem.Generic.MaxBearersCount)
+	}
+}
+
+func TestLocationValues(t *testing.T) {
+	var response struct {
+		Modem struct {
+			Location LocationInfo `json:"location"`
+		} `json:"modem"`
+	}
+	data := `{"modem": {"location": {
+		"3gpp": {"mcc": "262", "mnc": "01", "lac": "FFFE", "cid": "01A2B3C4", "tac": "--"},
+		"gps": {"latitude": "52.520008", "longitude": "13.404954", "altitude": "41.200000", "utc": "101530.00", "nmea": []},
+		"cdma-bs": {"latitude": "--", "longitude": "--"}
+	}}}`
+	if err := json.Unmarshal([]byte(data), &response); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	location := response.Modem.Location
+
+	if location.ThreeGPP.LACNumber == nil || *location.ThreeGPP.LACNumber != 0xFFFE {
+		t.Errorf("Unexpected LAC %v", location.ThreeGPP.LACNumber)
+	}
+	if location.ThreeGPP.CIDNumber == nil || *location.ThreeGPP.CIDNumber != 0x01A2B3C4 {
+		t.Errorf("Unexpected CID %v", location.ThreeGPP.CIDNumber)
+	}
+	if location.ThreeGPP.TACNumber != nil {
+		t.Errorf("Expected no TAC, got %d", *location.ThreeGPP.TACNumber)
+	}
+	if gps := location.GPS; gps.LatitudeDegrees == nil || *gps.LatitudeDegrees != 52.520008 ||
+		gps.AltitudeMeters == nil || *gps.AltitudeMeters != 41.2 {
+		t.Errorf("Unexpected GPS location %+v", gps)
+	}
+	if location.CDMABS.LatitudeDegrees != nil || location.CDMABS.LongitudeDegrees != nil {
+		t.Errorf("Expected no CDMA location, got %+v", location.CDMABS)
+	}
+}
+
+func TestStatusValues(t *testing.T) {
+	var status LocationStatus
+	if err := json.Unmarshal([]byte(`{"signals": "yes", "gps": {"refresh-rate": "30 seconds"}}`), &status); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if !status.SignalsEnabled || status.GPS.RefreshRateSeconds == nil || *status.GPS.RefreshRateSeconds != 30 {
+		t.Errorf("Unexpected location status %+v", status)
+	}
+
+	var sim SIMInfo
+	if err := json.Unmarshal([]byte(`{"properties": {"active": "yes", "eid": "--"}}`), &sim); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if !sim.Properties.IsActive || IsSet(sim.Properties.EID) {
+		t.Errorf("Unexpected SIM properties %+v", sim.Properties)
+	}
+}
+
+func TestSMSValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  SMSProperties
+	}{
+		{
+			name:  "unset",
+			input: `{"class": "--", "delivery-report-request": "--", "data": "--", "validity": "--", "timestamp": "--"}`,
+			want:  SMSProperties{Validity: "--", Timestamp: "--"},
+		},
+		{
+			name:  "mmcli strings",
+			input: `{"class": "1", "delivery-report-request": "yes", "data": "48656C6C6F", "validity": "1440", "timestamp": "2024-03-15T11:15:30+01:00"}`,
+			want: SMSProperties{
+				Class:             1,
+				DeliveryReportReq: true,
+				Data:              []byte("Hello"),
+				Validity:          "1440",
+				Timestamp:         "2024-03-15T11:15:30+01:00",
+			},
+		},
+		{
+			name:  "typed",
+			input: `{"class": 2, "delivery-report-request": true}`,
+			want:  SMSProperties{Class: 2, DeliveryReportReq: true},
+		},
+	}
+
+	for _, tt := range tests {
+		var got SMSProperties
+		if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+			t.Errorf("%s: failed to unmarshal: %v", tt.name, err)
+			continue
+		}
+		tt.want.normalize()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+
+	var p SMSProperties
+	if err := json.Unmarshal([]byte(`{"validity": "1440", "timestamp": "2024-03-15T11:15:30+01:00"}`), &p); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if p.ValidityMinutes == nil || *p.ValidityMinutes != 1440 {
+		t.Errorf("Unexpected validity %v", p.ValidityMinutes)
+	}
+	if p.Time == nil || !p.Time.Equal(time.Date(2024, 3, 15, 10, 15, 30, 0, time.UTC)) {
+		t.Errorf("Unexpected time %v", p.Time)
+	}
+	if p.DischargeTime != nil {
+		t.Errorf("Expected no discharge time, got %v", p.DischargeTime)
+	}
+
+	if err := json.Unmarshal([]byte(`{"class": "high"}`), &p); err == nil {
+		t.Error("Expected an error for an invalid class")
+	}
+	if err := json.Unmarshal([]byte(`{"data": "xyz"}`), &p); err == nil {
+		t.Error("Expected an error for invalid data")
+	}
+}
+
+func TestSMSRoundTrip(t *testing.T) {
+	in := SMSInfo{Properties: SMSProperties{Number: "+4915112345678", Class: 1, Data: []byte{0x01, 0xAB}}}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	var out SMSInfo
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Failed to unmarshal %s: %v", data, err)
+	}
+	in.Normalize()
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Round trip changed %+v to %+v", in, out)
+	}
+}
@@ -0,0 +1,248 @@
+package mmcli
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a ModemManager or mmcli release version, e.g. 1.20.2
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	// Suffix holds anything following the numeric part, e.g. "-dev"
+	Suffix string
+}
+
+// versionRe matches the first version number in mmcli -V or -B output
+var versionRe = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?([^\s]*)`)
+
+// ParseVersion parses a version such as "1.20.2" or "1.23.4-dev"
+func ParseVersion(s string) (Version, error) {
+	m := versionRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || m[0] != strings.TrimSpace(s) {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	return versionFromMatch(m), nil
+}
+
+func versionFromMatch(m []string) Version {
+	v := Version{Suffix: m[4]}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	return v
+}
+
+// parseVersionOutput extracts the version from mmcli -V or -B output, e.g.
+// "mmcli 1.20.2" or "ModemManager daemon 1.20.2 running"
+func parseVersionOutput(out []byte) (Version, error) {
+	m := versionRe.FindStringSubmatch(string(out))
+	if m == nil {
+		return Version{}, fmt.Errorf("no version found in %q", strings.TrimSpace(string(out)))
+	}
+	return versionFromMatch(m), nil
+}
+
+// String returns the version in its usual form, e.g. "1.20.2"
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d%s", v.Major, v.Minor, v.Patch, v.Suffix)
+}
+
+// Compare returns -1, 0 or 1 if v is older than, equal to or newer than o.
+// The suffix is not taken into account.
+func (v Version) Compare(o Version) int {
+	switch {
+	case v.Major != o.Major:
+		return compareInt(v.Major, o.Major)
+	case v.Minor != o.Minor:
+		return compareInt(v.Minor, o.Minor)
+	default:
+		return compareInt(v.Patch, o.Patch)
+	}
+}
+
+// AtLeast reports whether v is the given version or newer
+func (v Version) AtLeast(major, minor, patch int) bool {
+	return v.Compare(Version{Major: major, Minor: minor, Patch: patch}) >= 0
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// optionVersions lists mmcli options that only exist in newer releases,
+// with the release that introduced them
+var optionVersions = map[string]Version{
+	"--inhibit":                              {Major: 1, Minor: 14},
+	"--inhibit-device":                       {Major: 1, Minor: 14},
+	"--location-inject-assistance-data":      {Major: 1, Minor: 10},
+	"--3gpp-set-initial-eps-bearer-settings": {Major: 1, Minor: 10},
+	"--set-primary-sim-slot":                 {Major: 1, Minor: 16},
+	"--3gpp-profile-manager-list":            {Major: 1, Minor: 18},
+	"--3gpp-profile-manager-set":             {Major: 1, Minor: 18},
+	"--3gpp-profile-manager-delete":          {Major: 1, Minor: 18},
+	"--3gpp-set-packet-service-state":        {Major: 1, Minor: 20},
+	"--3gpp-set-nr5g-registration-settings":  {Major: 1, Minor: 20},
+	"--get-cell-info":                        {Major: 1, Minor: 20},
+}
+
+// UnsupportedError is returned before invoking an option the installed mmcli
+// or the running daemon does not know about. It matches ErrUnsupported.
+type UnsupportedError struct {
+	Option    string  // mmcli option, e.g. --set-primary-sim-slot
+	Required  Version // release that introduced the option
+	Installed Version // lower of the mmcli and daemon releases
+}
+
+// Error implements the error interface
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("ModemManager %s does not support %s (requires %s)", e.Installed, e.Option, e.Required)
+}
+
+// Is reports whether target is ErrUnsupported
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+// GetVersion returns the version of the installed mmcli
+func GetVersion() (Version, error) {
+	return GetVersionContext(context.Background())
+}
+
+// GetVersionContext is like GetVersion but uses ctx to bound the mmcli invocation
+func GetVersionContext(ctx context.Context) (Version, error) {
+	return DefaultClient.Version(ctx)
+}
+
+// Version returns the version of the installed mmcli. The version is detected
+// once and cached unless it was set with WithVersion.
+func (c *Client) Version(ctx context.Context) (Version, error) {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+
+	if c.version != nil {
+		return *c.version, nil
+	}
+
+	out, err := c.run(ctx, "-V")
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to get mmcli version: %w", err)
+	}
+
+	v, err := parseVersionOutput(out)
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to parse mmcli version: %w", err)
+	}
+
+	c.version = &v
+	return v, nil
+}
+
+// GetDaemonVersion returns the version of the running ModemManager daemon
+func GetDaemonVersion() (Version, error) {
+	return GetDaemonVersionContext(context.Background())
+}
+
+// GetDaemonVersionContext is like GetDaemonVersion but uses ctx to bound the mmcli invocation
+func GetDaemonVersionContext(ctx context.Context) (Version, error) {
+	return DefaultClient.DaemonVersion(ctx)
+}
+
+// DaemonVersion returns the version of the running ModemManager daemon
+func (c *Client) DaemonVersion(ctx context.Context) (Version, error) {
+	out, err := c.run(ctx, "-B")
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to get daemon version: %w", err)
+	}
+
+	v, err := parseVersionOutput(out)
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to parse daemon version: %w", err)
+	}
+	return v, nil
+}
+
+// featureVersion returns the lower of the mmcli and daemon versions, as an
+// option only works if both know about it. The daemon version is detected
+// once and cached unless it was set with WithDaemonVersion; if the daemon
+// cannot be reached, the mmcli version is used alone until RefreshVersions
+// is called.
+func (c *Client) featureVersion(ctx context.Context) (Version, error) {
+	installed, err := c.Version(ctx)
+	if err != nil {
+		return Version{}, err
+	}
+
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	if !c.daemonChecked {
+		daemon, err := c.DaemonVersion(ctx)
+		if ctx.Err() != nil {
+			return Version{}, ctx.Err()
+		}
+		c.daemonChecked = true
+		if err == nil {
+			c.daemonVersion = &daemon
+		}
+	}
+	if c.daemonVersion != nil && c.daemonVersion.Compare(installed) < 0 {
+		return *c.daemonVersion, nil
+	}
+	return installed, nil
+}
+
+// RefreshVersions makes the default client detect the mmcli and daemon versions again
+func RefreshVersions() {
+	DefaultClient.RefreshVersions()
+}
+
+// RefreshVersions forgets the detected mmcli and daemon versions, so that
+// they are detected again on the next version-gated call, e.g. after
+// ModemManager was started or upgraded. Versions set with WithVersion or
+// WithDaemonVersion are kept.
+func (c *Client) RefreshVersions() {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	if !c.versionFixed {
+		c.version = nil
+	}
+	if !c.daemonFixed {
+		c.daemonVersion = nil
+		c.daemonChecked = false
+	}
+}
+
+// checkSupported returns an *UnsupportedError if one of the arguments is an
+// option the installed mmcli or the running daemon does not know about. The
+// versions are only detected when such an option is used.
+func (c *Client) checkSupported(ctx context.Context, args []string) error {
+	for _, arg := range args {
+		option, _, _ := strings.Cut(arg, "=")
+		required, ok := optionVersions[option]
+		if !ok {
+			continue
+		}
+
+		installed, err := c.featureVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if installed.Compare(required) < 0 {
+			return &UnsupportedError{Option: option, Required: required, Installed: installed}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package mmcli
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Version
+		wantErr bool
+	}{
+		{input: "1.20.2", want: Version{Major: 1, Minor: 20, Patch: 2}},
+		{input: "1.22", want: Version{Major: 1, Minor: 22}},
+		{input: "1.23.4-dev", want: Version{Major: 1, Minor: 23, Patch: 4, Suffix: "-dev"}},
+		{input: "mmcli 1.20.2", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseVersion(%q): expected an error", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseVersion(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseVersion(%q) = %+v, expected %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	v := Version{Major: 1, Minor: 20, Patch: 2}
+
+	if v.Compare(Version{Major: 1, Minor: 20, Patch: 2, Suffix: "-dev"}) != 0 {
+		t.Error("Expected the suffix to be ignored")
+	}
+	if v.Compare(Version{Major: 1, Minor: 9, Patch: 9}) != 1 {
+		t.Error("Expected 1.20.2 to be newer than 1.9.9")
+	}
+	if v.Compare(Version{Major: 2}) != -1 {
+		t.Error("Expected 1.20.2 to be older than 2.0.0")
+	}
+	if !v.AtLeast(1, 20, 0) || v.AtLeast(1, 20, 3) {
+		t.Error("Unexpected AtLeast result")
+	}
+	if v.String() != "1.20.2" {
+		t.Errorf("Expected 1.20.2, got %s", v)
+	}
+}
+
+func TestClientVersions(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-V": {Stdout: []byte("mmcli 1.20.2\nCopyright (2011 - 2022) Aleksander Morgado\nLicense GPLv2+: GNU GPL version 2 or later <http://gnu.org/licenses/gpl-2.0.html>\n")},
+		"-B": {Stdout: []byte("ModemManager daemon 1.22.0 running\n")},
+	}}
+	client := NewClient(runner)
+
+	for i := 0; i < 2; i++ {
+		v, err := client.Version(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to get mmcli version: %v", err)
+		}
+		if v != (Version{Major: 1, Minor: 20, Patch: 2}) {
+			t.Errorf("Unexpected mmcli version %s", v)
+		}
+	}
+
+	v, err := client.DaemonVersion(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to get daemon version: %v", err)
+	}
+	if v != (Version{Major: 1, Minor: 22}) {
+		t.Errorf("Unexpected daemon version %s", v)
+	}
+
+	// the mmcli version is cached
+	expected := [][]string{{"-V"}, {"-B"}}
+	if !reflect.DeepEqual(runner.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, runner.calls)
+	}
+}
+
+func TestFeatureGating(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-V": {Stdout: []byte("mmcli 1.14.0\n")},
+		"-B": {Stdout: []byte("ModemManager daemon 1.20.0 running\n")},
+		"-m 0 --location-inject-assistance-data=/tmp/xtra3grc.bin": {},
+	}}
+	client := NewClient(runner)
+	ctx := context.Background()
+
+	// plain options never trigger version detection
+	if _, err := client.ResetModem(ctx, "0"); err == nil {
+		t.Fatal("Expected the unscripted reset to fail")
+	}
+	if len(runner.calls) != 1 {
+		t.Fatalf("Expected a single call, got %v", runner.calls)
+	}
+
+	err := client.SetPrimarySIMSlot(ctx, "0", 2)
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+	var unsupported *UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Option != "--set-primary-sim-slot" ||
+		unsupported.Required != (Version{Major: 1, Minor: 16}) {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if _, err := client.ListProfiles(ctx, "0"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+
+	if err := client.InjectAssistanceData(ctx, "0", "/tmp/xtra3grc.bin"); err != nil {
+		t.Errorf("Failed to inject assistance data: %v", err)
+	}
+
+	expected := [][]string{
+		{"-m", "0", "--reset"},
+		{"-V"},
+		{"-B"},
+		{"-m", "0", "--location-inject-assistance-data=/tmp/xtra3grc.bin"},
+	}
+	if !reflect.DeepEqual(runner.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, runner.calls)
+	}
+}
+
+func TestFeatureGatingDaemonVersion(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-V":                                  {Stdout: []byte("mmcli 1.22.0\n")},
+		"-B":                                  {Stdout: []byte("ModemManager daemon 1.18.2 running\n")},
+		"-m 0 --3gpp-profile-manager-list -J": {Stdout: []byte(`{"modem.3gpp.profile-manager.list": []}`)},
+	}}
+	client := NewClient(runner)
+	ctx := context.Background()
+
+	// the profile manager shipped in 1.18
+	if _, err := client.ListProfiles(ctx, "0"); err != nil {
+		t.Errorf("Failed to list profiles with a 1.18 daemon: %v", err)
+	}
+
+	// the older daemon limits the options mmcli supports
+	err := client.checkSupported(ctx, []string{"--get-cell-info"})
+	var unsupported *UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Installed != (Version{Major: 1, Minor: 18, Patch: 2}) {
+		t.Errorf("Expected the daemon version to be checked, got %v", err)
+	}
+
+	client = NewClient(runner, WithVersion(Version{Major: 1, Minor: 22}), WithDaemonVersion(Version{Major: 1, Minor: 16}))
+	runner.calls = nil
+	if _, err := client.ListProfiles(ctx, "0"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported with a 1.16 daemon, got %v", err)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("Expected no version detection, got %v", runner.calls)
+	}
+}
+
+func TestFeatureGatingDaemonDown(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-V": {Stdout: []byte("mmcli 1.22.0\n")},
+		"-B": {Stderr: []byte("error: couldn't find the ModemManager process in the bus\n"), ExitCode: 1},
+	}}
+	client := NewClient(runner)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := client.checkSupported(ctx, []string{"--get-cell-info"}); err != nil {
+			t.Errorf("Expected the mmcli version to be used alone, got %v", err)
+		}
+	}
+	expected := [][]string{{"-V"}, {"-B"}}
+	if !reflect.DeepEqual(runner.calls, expected) {
+		t.Errorf("Expected the failed daemon lookup to be cached, got %v", runner.calls)
+	}
+
+	// the daemon is up now
+	runner.responses["-B"] = &Result{Stdout: []byte("ModemManager daemon 1.16.0 running\n")}
+	client.RefreshVersions()
+	if err := client.checkSupported(ctx, []string{"--get-cell-info"}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected the daemon version to be checked after a refresh, got %v", err)
+	}
+	expected = append(expected, []string{"-V"}, []string{"-B"})
+	if !reflect.DeepEqual(runner.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, runner.calls)
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{
+		"-m 0 --3gpp-profile-manager-list -J": {Stdout: []byte(`{"modem.3gpp.profile-manager.list": [
+			"profile-id: 1, apn: internet.telekom, ip-type: ipv4v6",
+			"profile-id: 2, apn: ims, ip-type: ipv4v6"
+		]}`)},
+	}}
+	client := NewClient(runner, WithVersion(Version{Major: 1, Minor: 22}))
+
+	profiles, err := client.ListProfiles(context.Background(), "0")
+	if err != nil {
+		t.Fatalf("Failed to list profiles: %v", err)
+	}
+	if len(profiles) != 2 || profiles[1] != "profile-id: 2, apn: ims, ip-type: ipv4v6" {
+		t.Errorf("Unexpected profiles %v", profiles)
+	}
+}
@@ -0,0 +1,191 @@
+package mmcli
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"regexp"
+	"time"
+)
+
+// ModemEventType tells whether a modem appeared or disappeared
+type ModemEventType int
+
+// Modem event types
+const (
+	ModemAdded ModemEventType = iota
+	ModemRemoved
+)
+
+// String returns "added" or "removed"
+func (t ModemEventType) String() string {
+	if t == ModemRemoved {
+		return "removed"
+	}
+	return "added"
+}
+
+// ModemEvent reports a modem appearing or disappearing, e.g. on a USB reset
+type ModemEvent struct {
+	Type  ModemEventType
+	Path  string // D-Bus path of the modem
+	Index int    // modem index encoded in Path
+
+	// Only known if mmcli printed them
+	Manufacturer string
+	Model        string
+}
+
+// modemEventRe matches the modem lines mmcli -M prints, e.g.
+// "(+) /org/freedesktop/ModemManager1/Modem/0 [Quectel] EC25"
+var modemEventRe = regexp.MustCompile(`^\s*(\([+-]\))?\s*(/org/freedesktop/ModemManager1/Modem/\d+)(?:\s+\[([^\]]*)\]\s*(.*?))?\s*$`)
+
+// ParseModemEvent parses a line printed by mmcli -M. Lines without a marker
+// list a modem that is present and are reported as added. ok is false for
+// lines that do not describe a modem.
+func ParseModemEvent(line string) (event ModemEvent, ok bool) {
+	m := modemEventRe.FindStringSubmatch(line)
+	if m == nil {
+		return ModemEvent{}, false
+	}
+	index, err := ModemIndexFromPath(m[2])
+	if err != nil {
+		return ModemEvent{}, false
+	}
+
+	event = ModemEvent{Path: m[2], Index: index, Manufacturer: m[3], Model: m[4]}
+	if m[1] == "(-)" {
+		event.Type = ModemRemoved
+	}
+	return event, true
+}
+
+// WatchModems reports modems appearing and disappearing until ctx is cancelled
+func WatchModems(ctx context.Context) (<-chan ModemEvent, error) {
+	return DefaultClient.WatchModems(ctx)
+}
+
+// WatchModems reports modems appearing and disappearing until ctx is
+// cancelled, after which the channel is closed.
+//
+// The modems present when watching starts are reported as added first. The
+// events come from a long-lived mmcli -M, which is restarted if it exits; the
+// modems that disappeared in the meantime are then reported as removed. If
+// the modems cannot be listed when watching starts, e.g. because
+// ModemManager is still starting up, they are listed again once the watcher
+// runs. The client's runner must implement StreamRunner.
+func (c *Client) WatchModems(ctx context.Context) (<-chan ModemEvent, error) {
+	paths, listErr := c.ListModems(ctx)
+	if listErr != nil && ctx.Err() != nil {
+		return nil, listErr
+	}
+	stream, err := c.stream(ctx, "-M")
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan ModemEvent)
+	w := &modemWatcher{client: c, events: events, present: make(map[string]bool)}
+	go func() {
+		defer close(events)
+		if listErr != nil && !w.reconcile(ctx) {
+			stream.Close()
+			return
+		}
+		for _, path := range paths {
+			if event, ok := ParseModemEvent(path); ok && !w.emit(ctx, event) {
+				stream.Close()
+				return
+			}
+		}
+		w.run(ctx, stream)
+	}()
+	return events, nil
+}
+
+type modemWatcher struct {
+	client  *Client
+	events  chan<- ModemEvent
+	present map[string]bool
+}
+
+// run forwards the events of mmcli -M and restarts it until ctx is cancelled
+func (w *modemWatcher) run(ctx context.Context, stream io.ReadCloser) {
+	for {
+		start := time.Now()
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			event, ok := ParseModemEvent(scanner.Text())
+			if ok && !w.emit(ctx, event) {
+				stream.Close()
+				return
+			}
+		}
+		err := stream.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		w.client.logInvocation(ctx, []string{"-M"}, time.Since(start), nil, err)
+
+		for {
+			if !sleep(ctx, streamRestartDelay) {
+				return
+			}
+			if !w.reconcile(ctx) {
+				return
+			}
+			if stream, err = w.client.stream(ctx, "-M"); err == nil {
+				break
+			}
+			w.client.logInvocation(ctx, []string{"-M"}, 0, nil, err)
+		}
+	}
+}
+
+// reconcile reports the modems that disappeared while mmcli -M was not
+// running. It returns false if ctx was cancelled.
+func (w *modemWatcher) reconcile(ctx context.Context) bool {
+	paths, err := w.client.ListModems(ctx)
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	listed := make(map[string]bool)
+	for _, path := range paths {
+		listed[path] = true
+	}
+	for path := range w.present {
+		if listed[path] {
+			continue
+		}
+		event, ok := ParseModemEvent("(-) " + path)
+		if ok && !w.emit(ctx, event) {
+			return false
+		}
+	}
+	for _, path := range paths {
+		if event, ok := ParseModemEvent(path); ok && !w.emit(ctx, event) {
+			return false
+		}
+	}
+	return true
+}
+
+// emit delivers an event unless it repeats the modem's current presence. It
+// returns false if ctx was cancelled.
+func (w *modemWatcher) emit(ctx context.Context, event ModemEvent) bool {
+	added := event.Type == ModemAdded
+	if w.present[event.Path] == added {
+		return true
+	}
+	select {
+	case w.events <- event:
+	case <-ctx.Done():
+		return false
+	}
+	if added {
+		w.present[event.Path] = true
+	} else {
+		delete(w.present, event.Path)
+	}
+	return true
+}
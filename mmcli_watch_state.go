@@ -0,0 +1,340 @@
+package mmcli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// StateEventType tells what a StateEvent reports
+type StateEventType int
+
+// State event types
+const (
+	// StateInitial reports the state of a modem when watching starts or when
+	// the modem appears again after it was removed
+	StateInitial StateEventType = iota
+	// StateChanged reports a state transition
+	StateChanged
+	// StateRemoved reports that the modem disappeared
+	StateRemoved
+)
+
+// String returns "initial", "changed" or "removed"
+func (t StateEventType) String() string {
+	switch t {
+	case StateChanged:
+		return "changed"
+	case StateRemoved:
+		return "removed"
+	}
+	return "initial"
+}
+
+// StateEvent reports the state of a watched modem. Old is ModemStateUnknown
+// for StateInitial events, New is ModemStateUnknown for StateRemoved events.
+type StateEvent struct {
+	Type   StateEventType
+	Path   string // D-Bus path of the modem
+	Old    ModemState
+	New    ModemState
+	Reason StateChangeReason
+}
+
+// String returns the event as a concise log line, e.g.
+// "state: registered -> connecting (user-requested)"
+func (e StateEvent) String() string {
+	switch e.Type {
+	case StateInitial:
+		return fmt.Sprintf("state: %s", e.New)
+	case StateRemoved:
+		return fmt.Sprintf("state: %s -> removed", e.Old)
+	}
+	return fmt.Sprintf("state: %s -> %s (%s)", e.Old, e.New, e.Reason)
+}
+
+// stateEventRe matches the lines mmcli -w prints, e.g.
+// "/org/freedesktop/ModemManager1/Modem/0: State changed, 'registered' --> 'connecting' (Reason: user-requested)"
+var stateEventRe = regexp.MustCompile(`^\s*(/org/freedesktop/ModemManager1/Modem/\d+): (?:Initial state, '([^']*)'|State changed, '([^']*)' --> '([^']*)'(?: \(Reason: ([^)]*)\))?|(Removed))`)
+
+// ParseStateEvent parses a line printed by mmcli -w. ok is false for lines
+// that do not describe the modem state.
+func ParseStateEvent(line string) (event StateEvent, ok bool) {
+	m := stateEventRe.FindStringSubmatch(line)
+	if m == nil {
+		return StateEvent{}, false
+	}
+
+	event.Path = m[1]
+	switch {
+	case m[6] != "":
+		event.Type = StateRemoved
+	case m[3] != "":
+		event.Type = StateChanged
+		event.Old, _ = ParseModemState(m[3])
+		event.New, _ = ParseModemState(m[4])
+		event.Reason, _ = ParseStateChangeReason(m[5])
+	default:
+		event.New, _ = ParseModemState(m[2])
+	}
+	return event, true
+}
+
+// WatchState reports the state transitions of a modem until ctx is cancelled
+func WatchState(ctx context.Context, modemID string) (<-chan StateEvent, error) {
+	return DefaultClient.WatchState(ctx, modemID)
+}
+
+// WatchState reports the state transitions of a modem until ctx is
+// cancelled, after which the channel is closed.
+//
+// The current state is reported as StateInitial first. If the modem
+// disappears, StateRemoved is reported and watching continues: once a modem
+// with the same ID is present again, its state is reported as StateInitial.
+// Use WatchModems to follow a modem that reappears under another index.
+//
+// Watching the same modem several times shares a single long-lived mmcli -w,
+// also if it is selected once by index and once by D-Bus path; every caller
+// receives all events on its own channel. A caller that falls behind receives
+// intermediate transitions merged into one. The client's runner must
+// implement StreamRunner.
+func (c *Client) WatchState(ctx context.Context, modemID string) (<-chan StateEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	key := stateMonitorKey(modemID)
+	m := c.stateMonitors[key]
+	if m == nil {
+		// the monitor outlives ctx if others subscribe, so it only keeps its values
+		monitorCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		args := []string{"-m", key, "-w"}
+		stream, err := c.stream(monitorCtx, args...)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to watch modem state: %w", err)
+		}
+
+		m = &stateMonitor{client: c, args: args, cancel: cancel, subscribers: make(map[*stateSubscriber]bool)}
+		if c.stateMonitors == nil {
+			c.stateMonitors = make(map[string]*stateMonitor)
+		}
+		c.stateMonitors[key] = m
+		go m.run(monitorCtx, stream)
+	}
+
+	sub := m.subscribe()
+	go func() {
+		sub.deliver(ctx)
+		c.unsubscribe(key, m, sub)
+		close(sub.events)
+	}()
+	return sub.events, nil
+}
+
+// stateMonitorKey returns the modem index for a D-Bus path or index, so that
+// both select the same monitor. Other selectors are used as they are.
+func stateMonitorKey(modemID string) string {
+	if index, err := ModemIndexFromPath(modemID); err == nil {
+		return strconv.Itoa(index)
+	}
+	if index, err := strconv.Atoi(modemID); err == nil && index >= 0 {
+		return strconv.Itoa(index)
+	}
+	return modemID
+}
+
+// unsubscribe removes a subscriber and stops the monitor after the last one
+func (c *Client) unsubscribe(key string, m *stateMonitor, sub *stateSubscriber) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	m.mu.Lock()
+	delete(m.subscribers, sub)
+	last := len(m.subscribers) == 0
+	m.mu.Unlock()
+
+	if last {
+		m.cancel()
+		delete(c.stateMonitors, key)
+	}
+}
+
+// stateMonitor runs mmcli -w for one modem and fans its events out
+type stateMonitor struct {
+	client *Client
+	args   []string
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	subscribers map[*stateSubscriber]bool
+	present     bool
+	path        string
+	state       ModemState
+}
+
+// run forwards the events of mmcli -w and restarts it until ctx is cancelled
+func (m *stateMonitor) run(ctx context.Context, stream io.ReadCloser) {
+	for {
+		start := time.Now()
+		found := false
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			event, ok := ParseStateEvent(scanner.Text())
+			if !ok {
+				continue
+			}
+			if event.Type == StateInitial {
+				found = true
+			}
+			m.update(event)
+		}
+		err := stream.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		if !found {
+			// mmcli did not find the modem
+			m.update(StateEvent{Type: StateRemoved})
+		}
+		m.client.logInvocation(ctx, m.args, time.Since(start), nil, err)
+
+		for {
+			if !sleep(ctx, streamRestartDelay) {
+				return
+			}
+			if stream, err = m.client.stream(ctx, m.args...); err == nil {
+				break
+			}
+			m.client.logInvocation(ctx, m.args, 0, nil, err)
+		}
+	}
+}
+
+// update records an event and publishes it unless it repeats what the
+// subscribers already know. An initial state after an unexpected restart of
+// mmcli is reported as a transition from the last known state.
+func (m *stateMonitor) update(event StateEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch event.Type {
+	case StateInitial:
+		if m.present {
+			if event.New == m.state {
+				return
+			}
+			event = StateEvent{Type: StateChanged, Path: event.Path, Old: m.state, New: event.New}
+		}
+		m.present, m.path = true, event.Path
+	case StateChanged:
+		m.present, m.path = true, event.Path
+	case StateRemoved:
+		if !m.present {
+			return
+		}
+		event = StateEvent{Type: StateRemoved, Path: m.path, Old: m.state}
+		m.present = false
+	}
+	m.state = event.New
+
+	for sub := range m.subscribers {
+		sub.push(event)
+	}
+}
+
+// subscribe adds a subscriber, which first receives the current state
+func (m *stateMonitor) subscribe() *stateSubscriber {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &stateSubscriber{events: make(chan StateEvent), wake: make(chan struct{}, 1)}
+	if m.present {
+		sub.push(StateEvent{Type: StateInitial, Path: m.path, New: m.state})
+	}
+	m.subscribers[sub] = true
+	return sub
+}
+
+// maxQueuedStateEvents bounds the events queued for a slow subscriber
+const maxQueuedStateEvents = 32
+
+// stateSubscriber queues events for one subscriber, so that a slow
+// subscriber does not hold up the others
+type stateSubscriber struct {
+	events chan StateEvent
+	wake   chan struct{}
+
+	mu    sync.Mutex
+	queue []StateEvent
+}
+
+func (s *stateSubscriber) push(event StateEvent) {
+	s.mu.Lock()
+	s.queue = append(s.queue, event)
+	if len(s.queue) > maxQueuedStateEvents {
+		s.queue = coalesceStateEvents(s.queue)
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// coalesceStateEvents shortens the queue by merging the oldest transition
+// into the event before it, so that the subscriber still sees a consistent
+// sequence of states. If there is none, e.g. because the modem keeps
+// disappearing, the oldest event is dropped.
+func coalesceStateEvents(queue []StateEvent) []StateEvent {
+	for i := 0; i+1 < len(queue); i++ {
+		prev, next := &queue[i], queue[i+1]
+		if next.Type != StateChanged || prev.Type == StateRemoved {
+			continue
+		}
+		prev.New = next.New
+		if prev.Type == StateChanged {
+			prev.Reason = next.Reason
+		}
+		queue = append(queue[:i+1], queue[i+2:]...)
+		if prev.Type == StateChanged && prev.Old == prev.New {
+			// the transitions cancelled each other out
+			queue = append(queue[:i], queue[i+1:]...)
+		}
+		return queue
+	}
+	return queue[1:]
+}
+
+// deliver sends the queued events until ctx is cancelled
+func (s *stateSubscriber) deliver(ctx context.Context) {
+	for {
+		s.mu.Lock()
+		queue := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+
+		for _, event := range queue {
+			select {
+			case s.events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-s.wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package mmcli
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pipeRunner hands the writing end of every started stream to the test
+type pipeRunner struct {
+	scriptedRunner
+	streams chan *io.PipeWriter
+}
+
+func (r *pipeRunner) Stream(ctx context.Context, args ...string) (io.ReadCloser, error) {
+	if strings.Join(args, " ") != "-m 0 -w" {
+		return nil, io.ErrUnexpectedEOF
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		<-ctx.Done()
+		pw.Close()
+	}()
+	r.streams <- pw
+	return pr, nil
+}
+
+func TestParseStateEvent(t *testing.T) {
+	const path = "/org/freedesktop/ModemManager1/Modem/0"
+	tests := []struct {
+		line  string
+		event StateEvent
+		ok    bool
+	}{
+		{
+			line:  "\t" + path + ": Initial state, 'registered'.",
+			event: StateEvent{Type: StateInitial, Path: path, New: ModemStateRegistered},
+			ok:    true,
+		},
+		{
+			line:  "\t" + path + ": State changed, 'registered' --> 'connecting' (Reason: user-requested)",
+			event: StateEvent{Type: StateChanged, Path: path, Old: ModemStateRegistered, New: ModemStateConnecting, Reason: StateChangeReasonUserRequested},
+			ok:    true,
+		},
+		{
+			line:  path + ": State changed, 'connected' --> 'failed' (Reason: failure)",
+			event: StateEvent{Type: StateChanged, Path: path, Old: ModemStateConnected, New: ModemStateFailed, Reason: StateChangeReasonFailure},
+			ok:    true,
+		},
+		{
+			line:  "\t" + path + ": Removed",
+			event: StateEvent{Type: StateRemoved, Path: path},
+			ok:    true,
+		},
+		{line: "error: couldn't find modem"},
+	}
+
+	for _, tt := range tests {
+		event, ok := ParseStateEvent(tt.line)
+		if ok != tt.ok || event != tt.event {
+			t.Errorf("ParseStateEvent(%q) = %+v, %v, expected %+v, %v", tt.line, event, ok, tt.event, tt.ok)
+		}
+	}
+}
+
+func receiveState(t *testing.T, events <-chan StateEvent) StateEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("Event channel closed")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for an event")
+	}
+	return StateEvent{}
+}
+
+func TestWatchState(t *testing.T) {
+	defer func(d time.Duration) { streamRestartDelay = d }(streamRestartDelay)
+	streamRestartDelay = time.Millisecond
+
+	const path = "/org/freedesktop/ModemManager1/Modem/0"
+	runner := &pipeRunner{scriptedRunner: scriptedRunner{responses: map[string]*Result{}}, streams: make(chan *io.PipeWriter, 1)}
+	client := NewClient(runner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first, err := client.WatchState(ctx, "0")
+	if err != nil {
+		t.Fatalf("Failed to watch state: %v", err)
+	}
+	mmcli := <-runner.streams
+	io.WriteString(mmcli, "\t"+path+": Initial state, 'registered'.\n")
+	io.WriteString(mmcli, "\t"+path+": State changed, 'registered' --> 'connecting' (Reason: user-requested)\n")
+	if event := receiveState(t, first); event.Type != StateInitial || event.New != ModemStateRegistered {
+		t.Errorf("Expected initial state registered, got %+v", event)
+	}
+	if event := receiveState(t, first); event.String() != "state: registered -> connecting (user-requested)" {
+		t.Errorf("Expected transition to connecting, got %s", event)
+	}
+
+	// a second subscriber shares mmcli -w and starts with the current state
+	secondCtx, secondCancel := context.WithCancel(ctx)
+	second, err := client.WatchState(secondCtx, "0")
+	if err != nil {
+		t.Fatalf("Failed to watch state: %v", err)
+	}
+	if event := receiveState(t, second); event.Type != StateInitial || event.New != ModemStateConnecting {
+		t.Errorf("Expected initial state connecting, got %+v", event)
+	}
+
+	io.WriteString(mmcli, "\t"+path+": State changed, 'connecting' --> 'connected' (Reason: user-requested)\n")
+	for _, events := range []<-chan StateEvent{first, second} {
+		if event := receiveState(t, events); event.Type != StateChanged || event.New != ModemStateConnected {
+			t.Errorf("Expected transition to connected, got %+v", event)
+		}
+	}
+	secondCancel()
+	for range second {
+	}
+
+	// the modem vanishes, mmcli exits and cannot find it after the restart
+	io.WriteString(mmcli, "\t"+path+": Removed\n")
+	mmcli.Close()
+	if event := receiveState(t, first); event.Type != StateRemoved || event.Old != ModemStateConnected || event.Path != path {
+		t.Errorf("Expected the modem to be removed, got %+v", event)
+	}
+	(<-runner.streams).Close()
+
+	// the modem is back
+	mmcli = <-runner.streams
+	io.WriteString(mmcli, "\t"+path+": Initial state, 'disabled'.\n")
+	if event := receiveState(t, first); event.Type != StateInitial || event.New != ModemStateDisabled {
+		t.Errorf("Expected initial state disabled, got %+v", event)
+	}
+
+	cancel()
+	for range first {
+	}
+	client.stateMu.Lock()
+	defer client.stateMu.Unlock()
+	if len(client.stateMonitors) != 0 {
+		t.Errorf("Expected the monitor to stop with the last subscriber, got %v", client.stateMonitors)
+	}
+}
+
+func TestWatchStateRequiresStreamRunner(t *testing.T) {
+	client := NewClient(&scriptedRunner{})
+	if _, err := client.WatchState(context.Background(), "0"); err == nil {
+		t.Error("Expected an error for a runner without streaming support")
+	}
+}
+
+func TestWatchStateSharesMonitor(t *testing.T) {
+	const path = "/org/freedesktop/ModemManager1/Modem/0"
+	runner := &pipeRunner{scriptedRunner: scriptedRunner{responses: map[string]*Result{}}, streams: make(chan *io.PipeWriter, 2)}
+	client := NewClient(runner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	byIndex, err := client.WatchState(ctx, "0")
+	if err != nil {
+		t.Fatalf("Failed to watch state: %v", err)
+	}
+	byPath, err := client.WatchState(ctx, path)
+	if err != nil {
+		t.Fatalf("Failed to watch state: %v", err)
+	}
+
+	mmcli := <-runner.streams
+	io.WriteString(mmcli, "\t"+path+": Initial state, 'registered'.\n")
+	for _, events := range []<-chan StateEvent{byIndex, byPath} {
+		if event := receiveState(t, events); event.Type != StateInitial || event.New != ModemStateRegistered {
+			t.Errorf("Expected initial state registered, got %+v", event)
+		}
+	}
+	select {
+	case <-runner.streams:
+		t.Error("Expected index and D-Bus path to share mmcli -w")
+	default:
+	}
+
+	cancel()
+	for range byIndex {
+	}
+	for range byPath {
+	}
+}
+
+func TestStateSubscriberQueueBounded(t *testing.T) {
+	states := []ModemState{ModemStateRegistered, ModemStateConnecting, ModemStateConnected, ModemStateDisconnecting}
+	sub := &stateSubscriber{events: make(chan StateEvent), wake: make(chan struct{}, 1)}
+	sub.push(StateEvent{Type: StateInitial, New: states[0]})
+	for i := 0; i < 1000; i++ {
+		sub.push(StateEvent{Type: StateChanged, Old: states[i%len(states)], New: states[(i+1)%len(states)]})
+	}
+	sub.push(StateEvent{Type: StateRemoved, Old: states[1000%len(states)]})
+
+	if len(sub.queue) > maxQueuedStateEvents {
+		t.Fatalf("Expected at most %d queued events, got %d", maxQueuedStateEvents, len(sub.queue))
+	}
+	if sub.queue[0].Type != StateInitial || sub.queue[len(sub.queue)-1].Type != StateRemoved {
+		t.Errorf("Expected the initial state and the removal to be kept, got %+v", sub.queue)
+	}
+	for i := 1; i < len(sub.queue); i++ {
+		if sub.queue[i].Old != sub.queue[i-1].New {
+			t.Errorf("Event %d does not start from the previous state: %+v", i, sub.queue)
+		}
+	}
+}
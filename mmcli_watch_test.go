@@ -0,0 +1,204 @@
+package mmcli
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// streamingRunner answers like scriptedRunner and plays back one output per
+// started stream. The last stream stays open until it is closed.
+type streamingRunner struct {
+	scriptedRunner
+
+	mu      sync.Mutex
+	outputs []string
+	streams [][]string
+}
+
+func (r *streamingRunner) Stream(ctx context.Context, args ...string) (io.ReadCloser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.streams = append(r.streams, args)
+
+	pr, pw := io.Pipe()
+	output := ""
+	last := len(r.outputs) <= 1
+	if len(r.outputs) > 0 {
+		output, r.outputs = r.outputs[0], r.outputs[1:]
+	}
+	go func() {
+		io.WriteString(pw, output)
+		if !last {
+			pw.Close()
+			return
+		}
+		<-ctx.Done()
+		pw.Close()
+	}()
+	return pr, nil
+}
+
+func (r *streamingRunner) setModemList(paths ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	quoted := make([]string, len(paths))
+	for i, path := range paths {
+		quoted[i] = `"` + path + `"`
+	}
+	r.responses["-J -L"] = &Result{Stdout: []byte(`{"modem-list": [` + strings.Join(quoted, ", ") + `]}`)}
+}
+
+func (r *streamingRunner) Run(ctx context.Context, args ...string) (*Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.scriptedRunner.Run(ctx, args...)
+}
+
+func TestParseModemEvent(t *testing.T) {
+	tests := []struct {
+		line  string
+		event ModemEvent
+		ok    bool
+	}{
+		{
+			line:  "(+) /org/freedesktop/ModemManager1/Modem/0 [Quectel] EC25",
+			event: ModemEvent{Type: ModemAdded, Path: "/org/freedesktop/ModemManager1/Modem/0", Index: 0, Manufacturer: "Quectel", Model: "EC25"},
+			ok:    true,
+		},
+		{
+			line:  "    (-) /org/freedesktop/ModemManager1/Modem/12 [Sierra Wireless, Incorporated] MC7455",
+			event: ModemEvent{Type: ModemRemoved, Path: "/org/freedesktop/ModemManager1/Modem/12", Index: 12, Manufacturer: "Sierra Wireless, Incorporated", Model: "MC7455"},
+			ok:    true,
+		},
+		{
+			line:  "/org/freedesktop/ModemManager1/Modem/3",
+			event: ModemEvent{Type: ModemAdded, Path: "/org/freedesktop/ModemManager1/Modem/3", Index: 3},
+			ok:    true,
+		},
+		{line: "No modems were found"},
+		{line: ""},
+	}
+
+	for _, tt := range tests {
+		event, ok := ParseModemEvent(tt.line)
+		if ok != tt.ok || event != tt.event {
+			t.Errorf("ParseModemEvent(%q) = %+v, %v, expected %+v, %v", tt.line, event, ok, tt.event, tt.ok)
+		}
+	}
+}
+
+func receive(t *testing.T, events <-chan ModemEvent) ModemEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("Event channel closed")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for an event")
+	}
+	return ModemEvent{}
+}
+
+func TestWatchModems(t *testing.T) {
+	defer func(d time.Duration) { streamRestartDelay = d }(streamRestartDelay)
+	streamRestartDelay = time.Millisecond
+
+	const modem0 = "/org/freedesktop/ModemManager1/Modem/0"
+	const modem1 = "/org/freedesktop/ModemManager1/Modem/1"
+	runner := &streamingRunner{
+		scriptedRunner: scriptedRunner{responses: map[string]*Result{}},
+		outputs: []string{
+			// mmcli -M repeats the present modem, then reports a new one and dies
+			"(+) " + modem0 + " [Quectel] EC25\n(+) " + modem1 + " [Quectel] EC25\n",
+			"(+) " + modem1 + " [Quectel] EC25\n(-) " + modem1 + " [Quectel] EC25\n",
+		},
+	}
+	runner.setModemList(modem0)
+	client := NewClient(runner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := client.WatchModems(ctx)
+	if err != nil {
+		t.Fatalf("Failed to watch modems: %v", err)
+	}
+
+	if event := receive(t, events); event.Type != ModemAdded || event.Path != modem0 {
+		t.Errorf("Expected modem 0 to be added first, got %+v", event)
+	}
+	// modem 0 disappears while mmcli -M is restarted
+	runner.setModemList(modem1)
+	if event := receive(t, events); event.Type != ModemAdded || event.Index != 1 || event.Model != "EC25" {
+		t.Errorf("Expected modem 1 to be added, got %+v", event)
+	}
+	if event := receive(t, events); event.Type != ModemRemoved || event.Path != modem0 {
+		t.Errorf("Expected modem 0 to be removed after the restart, got %+v", event)
+	}
+	if event := receive(t, events); event.Type != ModemRemoved || event.Path != modem1 {
+		t.Errorf("Expected modem 1 to be removed, got %+v", event)
+	}
+
+	cancel()
+	for range events {
+	}
+	runner.mu.Lock()
+	defer runner.mu.Unlock()
+	if len(runner.streams) != 2 || strings.Join(runner.streams[0], " ") != "-M" {
+		t.Errorf("Expected mmcli -M to be started twice, got %v", runner.streams)
+	}
+}
+
+// listFailingRunner fails the first failures modem listings
+type listFailingRunner struct {
+	*streamingRunner
+	failures int
+}
+
+func (r *listFailingRunner) Run(ctx context.Context, args ...string) (*Result, error) {
+	if strings.Join(args, " ") == "-J -L" && r.failures > 0 {
+		r.failures--
+		return &Result{Stderr: []byte("error: couldn't find the ModemManager process in the bus\n"), ExitCode: 1}, nil
+	}
+	return r.streamingRunner.Run(ctx, args...)
+}
+
+func TestWatchModemsInitialListError(t *testing.T) {
+	const modem0 = "/org/freedesktop/ModemManager1/Modem/0"
+	streaming := &streamingRunner{scriptedRunner: scriptedRunner{responses: map[string]*Result{}}}
+	streaming.setModemList(modem0)
+	runner := &listFailingRunner{streamingRunner: streaming, failures: 1}
+	client := NewClient(runner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := client.WatchModems(ctx)
+	if err != nil {
+		t.Fatalf("Expected watching to start despite the failed listing, got %v", err)
+	}
+	if event := receive(t, events); event.Type != ModemAdded || event.Path != modem0 {
+		t.Errorf("Expected modem 0 to be added once listed, got %+v", event)
+	}
+
+	cancel()
+	for range events {
+	}
+	streaming.mu.Lock()
+	defer streaming.mu.Unlock()
+	if len(streaming.streams) != 1 {
+		t.Errorf("Expected mmcli -M to be started once, got %v", streaming.streams)
+	}
+}
+
+func TestWatchModemsRequiresStreamRunner(t *testing.T) {
+	runner := &scriptedRunner{responses: map[string]*Result{"-J -L": {Stdout: []byte(`{"modem-list": []}`)}}}
+	client := NewClient(runner)
+	if _, err := client.WatchModems(context.Background()); err == nil {
+		t.Error("Expected an error for a runner without streaming support")
+	}
+}
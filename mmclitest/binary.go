@@ -0,0 +1,125 @@
+package mmclitest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// callLog is the file the fake binary appends its arguments to
+const callLog = "calls"
+
+// Install writes a fake mmcli executable serving the runner's expectations to
+// a temporary directory and puts that directory first on PATH for the rest of
+// the test, for code that runs mmcli itself (e.g. via mmcli.ExecRunner). Only
+// expectations with literal arguments are supported, and they must be added
+// before Install is called. Install returns the path of the executable.
+func (r *Runner) Install(t testing.TB) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&script, "dir=%s\n", shellQuote(dir))
+	script.WriteString(`printf '%s\037' "$@" >> "$dir/` + callLog + `"
+printf '\n' >> "$dir/` + callLog + `"
+stripped=""
+for arg in "$@"; do
+	case "$arg" in
+	--timeout=*) ;;
+	*) stripped="$stripped${stripped:+ }$arg" ;;
+	esac
+done
+for key in "$*" "$stripped"; do
+	case "$key" in
+`)
+
+	for i, e := range r.expectations {
+		if e.literal == nil && len(e.matchers) > 0 {
+			t.Fatalf("mmclitest: cannot install expectation mmcli %s: only literal arguments are supported", e.pattern)
+		}
+		if e.err != nil {
+			t.Fatalf("mmclitest: cannot install expectation mmcli %s: failures to run are not supported", e.pattern)
+		}
+
+		stdout := filepath.Join(dir, fmt.Sprintf("%d.out", i))
+		stderr := filepath.Join(dir, fmt.Sprintf("%d.err", i))
+		if err := os.WriteFile(stdout, e.result.Stdout, 0o644); err != nil {
+			t.Fatalf("mmclitest: failed to write fake mmcli output: %v", err)
+		}
+		if err := os.WriteFile(stderr, e.result.Stderr, 0o644); err != nil {
+			t.Fatalf("mmclitest: failed to write fake mmcli output: %v", err)
+		}
+
+		fmt.Fprintf(&script, "\t%s) cat %s; cat %s >&2; exit %d ;;\n",
+			shellQuote(strings.Join(e.literal, " ")), shellQuote(stdout), shellQuote(stderr), e.result.ExitCode)
+	}
+
+	script.WriteString(`	esac
+done
+echo "error: unexpected invocation" >&2
+exit 1
+`)
+
+	path := filepath.Join(dir, "mmcli")
+	if err := os.WriteFile(path, []byte(script.String()), 0o755); err != nil {
+		t.Fatalf("mmclitest: failed to write fake mmcli: %v", err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	r.binaries = append(r.binaries, dir)
+
+	// keep the calls once the temporary directory is gone
+	t.Cleanup(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.binaryCalls = append(r.binaryCalls, readCallLog(t, dir)...)
+		for i, d := range r.binaries {
+			if d == dir {
+				r.binaries = append(r.binaries[:i], r.binaries[i+1:]...)
+				break
+			}
+		}
+	})
+
+	return path
+}
+
+// installedCalls returns the invocations of all fake binaries. The caller must hold r.mu.
+func (r *Runner) installedCalls(t testing.TB) [][]string {
+	calls := append([][]string(nil), r.binaryCalls...)
+	for _, dir := range r.binaries {
+		calls = append(calls, readCallLog(t, dir)...)
+	}
+	return calls
+}
+
+// readCallLog returns the invocations recorded by a fake binary
+func readCallLog(t testing.TB, dir string) [][]string {
+	data, err := os.ReadFile(filepath.Join(dir, callLog))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Errorf("mmclitest: failed to read fake mmcli call log: %v", err)
+		return nil
+	}
+
+	var calls [][]string
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		args := strings.Split(line, "\x1f")
+		calls = append(calls, args[:len(args)-1])
+	}
+	return calls
+}
+
+// shellQuote quotes s for use as a single word in a shell script
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,87 @@
+package mmclitest
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// fixtures holds mmcli -J and -K output modelled on a Quectel EC25 registered
+// on a live network
+//
+//go:embed fixtures/*.json fixtures/*.txt
+var fixtures embed.FS
+
+// Fixture returns the bundled mmcli output with the given name, e.g. "modem"
+// or "location-status". It panics if there is no such fixture.
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile(path.Join("fixtures", name+".json"))
+	if err != nil {
+		panic(fmt.Sprintf("mmclitest: unknown fixture %q", name))
+	}
+	return data
+}
+
+// KeyValueFixture returns the bundled mmcli -K output with the given name.
+// It panics if there is no such fixture.
+func KeyValueFixture(name string) []byte {
+	data, err := fixtures.ReadFile(path.Join("fixtures", name+".txt"))
+	if err != nil {
+		panic(fmt.Sprintf("mmclitest: unknown fixture %q", name))
+	}
+	return data
+}
+
+// Fixtures returns the names of all bundled fixtures
+func Fixtures() []string {
+	entries, _ := fixtures.ReadDir("fixtures")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fixtureInvocations maps the bundled fixtures to the invocations producing them.
+// The fixtures describe modem 0 with SIM 0, bearer 0 and SMS 0.
+var fixtureInvocations = []struct {
+	args    []string
+	fixture string
+}{
+	{[]string{"-J", "-L"}, "modem-list"},
+	{[]string{"-m", "0", "-J"}, "modem"},
+	{[]string{"-i", "0", "-J"}, "sim"},
+	{[]string{"-b", "0", "-J"}, "bearer"},
+	{[]string{"-m", "0", "--location-status", "-J"}, "location-status"},
+	{[]string{"-m", "0", "--location-get", "-J"}, "location"},
+	{[]string{"-m", "0", "--messaging-status", "-J"}, "messaging-status"},
+	{[]string{"-m", "0", "--messaging-list-sms", "-J"}, "sms-list"},
+	{[]string{"-s", "0", "-J"}, "sms"},
+	{[]string{"-m", "0", "--time", "-J"}, "time"},
+}
+
+// OnFixtures adds optional expectations answering the queries for modem 0
+// with the bundled fixtures, in both JSON and key-value format. Expectations
+// added before OnFixtures take precedence, so individual answers can be
+// overridden.
+func (r *Runner) OnFixtures() *Runner {
+	for _, inv := range fixtureInvocations {
+		jsonArgs := make([]interface{}, len(inv.args))
+		keyValueArgs := make([]interface{}, len(inv.args))
+		for i, arg := range inv.args {
+			jsonArgs[i] = arg
+			keyValueArgs[i] = arg
+			if arg == "-J" {
+				keyValueArgs[i] = "-K"
+			}
+		}
+		r.On(jsonArgs...).ReturnFixture(inv.fixture).Maybe()
+		r.On(keyValueArgs...).Return(string(KeyValueFixture(inv.fixture))).Maybe()
+	}
+	return r
+}
@@ -0,0 +1,322 @@
+// Package mmclitest provides a scriptable fake mmcli for testing code that
+// uses the mmcli package without ModemManager or a modem being present.
+package mmclitest
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rescoot/go-mmcli"
+)
+
+// Matcher matches a single mmcli argument
+type Matcher func(arg string) bool
+
+// Any matches any argument
+func Any() Matcher {
+	return func(string) bool { return true }
+}
+
+// Prefix matches arguments starting with prefix, e.g. Prefix("--simple-connect=")
+func Prefix(prefix string) Matcher {
+	return func(arg string) bool { return strings.HasPrefix(arg, prefix) }
+}
+
+// Regexp matches arguments against a regular expression
+func Regexp(expr string) Matcher {
+	re := regexp.MustCompile(expr)
+	return re.MatchString
+}
+
+// Expectation describes an expected mmcli invocation and the result returned for it
+type Expectation struct {
+	matchers []Matcher
+	literal  []string
+	pattern  string
+	result   mmcli.Result
+	err      error
+	times    int
+	optional bool
+	open     bool
+	calls    int
+}
+
+// Return sets the standard output returned for the invocation
+func (e *Expectation) Return(stdout string) *Expectation {
+	e.result.Stdout = []byte(stdout)
+	return e
+}
+
+// ReturnFixture returns one of the bundled fixtures as standard output
+func (e *Expectation) ReturnFixture(name string) *Expectation {
+	e.result.Stdout = Fixture(name)
+	return e
+}
+
+// Stderr sets the standard error returned for the invocation
+func (e *Expectation) Stderr(stderr string) *Expectation {
+	e.result.Stderr = []byte(stderr)
+	return e
+}
+
+// Exit sets the exit code returned for the invocation
+func (e *Expectation) Exit(code int) *Expectation {
+	e.result.ExitCode = code
+	return e
+}
+
+// Fail makes the invocation fail as if mmcli could not be run at all
+func (e *Expectation) Fail(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// KeepOpen keeps a long-lived invocation such as mmcli -M running after its
+// output was read, until the stream is closed or its context is cancelled.
+// Without it the fake mmcli exits once its output was read.
+func (e *Expectation) KeepOpen() *Expectation {
+	e.open = true
+	return e
+}
+
+// Times sets the exact number of times the invocation is expected
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Once expects the invocation exactly once
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// Maybe allows the invocation to not happen at all
+func (e *Expectation) Maybe() *Expectation {
+	e.optional = true
+	return e
+}
+
+func (e *Expectation) match(args []string) bool {
+	if len(args) != len(e.matchers) {
+		return false
+	}
+	for i, m := range e.matchers {
+		if !m(args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.times > 0 && e.calls >= e.times
+}
+
+// Runner is a fake mmcli.Runner answering invocations from a list of expectations
+type Runner struct {
+	t            testing.TB
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        [][]string
+	binaries     []string
+	binaryCalls  [][]string
+}
+
+var _ mmcli.Runner = (*Runner)(nil)
+
+// NewRunner returns a Runner reporting unexpected invocations to t. Unmet
+// expectations are reported when the test finishes.
+func NewRunner(t testing.TB) *Runner {
+	r := &Runner{t: t}
+	t.Cleanup(func() { r.AssertExpectations(t) })
+	return r
+}
+
+// Client returns an mmcli.Client using the runner
+func (r *Runner) Client(opts ...mmcli.Option) *mmcli.Client {
+	return mmcli.NewClient(r, opts...)
+}
+
+// On adds an expectation for an invocation. Each argument is either a string,
+// which must match exactly, or a Matcher. Expectations are tried in the order
+// they were added; by default an expectation must be met at least once.
+func (r *Runner) On(args ...interface{}) *Expectation {
+	e := &Expectation{}
+	var pattern []string
+	literal := true
+	for _, arg := range args {
+		switch a := arg.(type) {
+		case string:
+			e.matchers = append(e.matchers, func(s string) bool { return s == a })
+			e.literal = append(e.literal, a)
+			pattern = append(pattern, a)
+		case Matcher:
+			e.matchers = append(e.matchers, a)
+			pattern = append(pattern, "<matcher>")
+			literal = false
+		case func(string) bool:
+			e.matchers = append(e.matchers, a)
+			pattern = append(pattern, "<matcher>")
+			literal = false
+		default:
+			panic(fmt.Sprintf("mmclitest: unsupported argument type %T", arg))
+		}
+	}
+	if !literal {
+		e.literal = nil
+	}
+	e.pattern = strings.Join(pattern, " ")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expectations = append(r.expectations, e)
+	return e
+}
+
+// Run answers an invocation from the first matching expectation. Arguments of
+// the form --timeout=N, which the client adds for context deadlines, are
+// ignored unless an expectation matches them explicitly.
+func (r *Runner) Run(ctx context.Context, args ...string) (*mmcli.Result, error) {
+	res, _, err := r.answer(ctx, args)
+	return res, err
+}
+
+// answer records an invocation and returns the result of the first matching
+// expectation, and whether the invocation is kept open
+func (r *Runner) answer(ctx context.Context, args []string) (*mmcli.Result, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, args)
+
+	e := r.find(args)
+	if e == nil {
+		e = r.find(withoutTimeout(args))
+	}
+	if e == nil {
+		r.t.Errorf("mmclitest: unexpected invocation: mmcli %s", strings.Join(args, " "))
+		return &mmcli.Result{Stderr: []byte("error: unexpected invocation"), ExitCode: 1}, false, nil
+	}
+
+	e.calls++
+	if e.err != nil {
+		return nil, false, e.err
+	}
+	res := e.result
+	return &res, e.open, nil
+}
+
+// find returns the first matching expectation that has calls left
+func (r *Runner) find(args []string) *Expectation {
+	for _, e := range r.expectations {
+		if e.match(args) && !e.exhausted() {
+			return e
+		}
+	}
+	return nil
+}
+
+func withoutTimeout(args []string) []string {
+	out := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--timeout=") {
+			out = append(out, arg)
+		}
+	}
+	return out
+}
+
+// Calls returns the arguments of all invocations so far, including those
+// made through an installed fake binary
+func (r *Runner) Calls() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append(append([][]string(nil), r.calls...), r.installedCalls(r.t)...)
+}
+
+// Called reports whether an invocation with exactly these arguments happened
+func (r *Runner) Called(args ...string) bool {
+	for _, call := range r.Calls() {
+		if equal(call, args) || equal(withoutTimeout(call), args) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertCalled fails the test if no invocation with exactly these arguments happened
+func (r *Runner) AssertCalled(t testing.TB, args ...string) {
+	t.Helper()
+	if !r.Called(args...) {
+		t.Errorf("mmclitest: expected invocation mmcli %s, got %s", strings.Join(args, " "), formatCalls(r.Calls()))
+	}
+}
+
+// AssertNotCalled fails the test if an invocation with exactly these arguments happened
+func (r *Runner) AssertNotCalled(t testing.TB, args ...string) {
+	t.Helper()
+	if r.Called(args...) {
+		t.Errorf("mmclitest: unexpected invocation mmcli %s", strings.Join(args, " "))
+	}
+}
+
+// AssertExpectations fails the test for every expectation that was not met
+func (r *Runner) AssertExpectations(t testing.TB) {
+	t.Helper()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the fake binary always answers from the first matching expectation
+	counts := make(map[*Expectation]int)
+	for _, call := range r.installedCalls(t) {
+		for _, e := range r.expectations {
+			if e.match(call) || e.match(withoutTimeout(call)) {
+				counts[e]++
+				break
+			}
+		}
+	}
+
+	for _, e := range r.expectations {
+		calls := e.calls + counts[e]
+		switch {
+		case e.times > 0 && calls != e.times && !(e.optional && calls == 0):
+			t.Errorf("mmclitest: expected mmcli %s %d times, got %d", e.pattern, e.times, calls)
+		case calls == 0 && !e.optional:
+			t.Errorf("mmclitest: expected invocation mmcli %s did not happen", e.pattern)
+		}
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func formatCalls(calls [][]string) string {
+	if len(calls) == 0 {
+		return "no invocations"
+	}
+	formatted := make([]string, len(calls))
+	for i, call := range calls {
+		formatted[i] = "mmcli " + strings.Join(call, " ")
+	}
+	return strings.Join(formatted, "; ")
+}
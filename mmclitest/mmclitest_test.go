@@ -0,0 +1,320 @@
+package mmclitest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rescoot/go-mmcli"
+)
+
+// recordingTB captures failures instead of failing the test
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Helper() {}
+
+func TestFixturesAreValidJSON(t *testing.T) {
+	for _, name := range Fixtures() {
+		if !json.Valid(Fixture(name)) {
+			t.Errorf("Fixture %s is not valid JSON", name)
+		}
+	}
+}
+
+func TestKeyValueFixtures(t *testing.T) {
+	for _, name := range Fixtures() {
+		converted, err := mmcli.KeyValueToJSON(KeyValueFixture(name))
+		if err != nil {
+			t.Errorf("Failed to convert key-value fixture %s: %v", name, err)
+			continue
+		}
+
+		var fromKeyValue, fromJSON interface{}
+		if err := json.Unmarshal(converted, &fromKeyValue); err != nil {
+			t.Fatalf("Invalid JSON from key-value fixture %s: %v", name, err)
+		}
+		if err := json.Unmarshal(Fixture(name), &fromJSON); err != nil {
+			t.Fatalf("Invalid JSON fixture %s: %v", name, err)
+		}
+		if !reflect.DeepEqual(fromKeyValue, fromJSON) {
+			t.Errorf("Key-value fixture %s differs from JSON fixture:\n%s", name, converted)
+		}
+	}
+}
+
+// TestFormatsDecodeIdentically checks that every query decodes to the same
+// result whether mmcli is asked for JSON or key-value output
+func TestFormatsDecodeIdentically(t *testing.T) {
+	r := NewRunner(t).OnFixtures()
+	jsonClient := r.Client()
+	keyValueClient := r.Client(mmcli.WithFormat(mmcli.FormatKeyValue))
+	ctx := context.Background()
+
+	queries := map[string]func(c *mmcli.Client) (interface{}, error){
+		"modem list": func(c *mmcli.Client) (interface{}, error) { return c.ListModems(ctx) },
+		"modem":      func(c *mmcli.Client) (interface{}, error) { return c.GetModemDetails(ctx, "0") },
+		"sim":        func(c *mmcli.Client) (interface{}, error) { return c.GetSIMInfo(ctx, "0") },
+		"location status": func(c *mmcli.Client) (interface{}, error) {
+			return c.GetLocationStatus(ctx, "0")
+		},
+		"location": func(c *mmcli.Client) (interface{}, error) { return c.GetLocation(ctx, "0") },
+		"messaging status": func(c *mmcli.Client) (interface{}, error) {
+			return c.GetMessagingStatus(ctx, "0")
+		},
+		"sms list": func(c *mmcli.Client) (interface{}, error) { return c.ListSMS(ctx, "0") },
+		"sms":      func(c *mmcli.Client) (interface{}, error) { return c.GetSMSInfo(ctx, "0") },
+		"time":     func(c *mmcli.Client) (interface{}, error) { return c.GetNetworkTime(ctx, "0") },
+	}
+
+	for name, query := range queries {
+		fromJSON, err := query(jsonClient)
+		if err != nil {
+			t.Errorf("%s: JSON query failed: %v", name, err)
+			continue
+		}
+		fromKeyValue, err := query(keyValueClient)
+		if err != nil {
+			t.Errorf("%s: key-value query failed: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(fromJSON, fromKeyValue) {
+			t.Errorf("%s: formats decode differently:\nJSON:      %+v\nkey-value: %+v", name, fromJSON, fromKeyValue)
+		}
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	r := NewRunner(t).OnFixtures()
+	client := r.Client()
+	ctx := context.Background()
+
+	id, err := client.GetFirstModemID(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get first modem ID: %v", err)
+	}
+	if id != "0" {
+		t.Errorf("Expected modem ID 0, got %s", id)
+	}
+
+	mm, err := client.GetModemDetails(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to get modem details: %v", err)
+	}
+	if !mm.IsConnected() {
+		t.Error("Expected modem to be connected")
+	}
+	if mm.Modem.Generic.Manufacturer != "Quectel" {
+		t.Errorf("Expected manufacturer Quectel, got %s", mm.Modem.Generic.Manufacturer)
+	}
+	if mm.Modem.ThreeGPP.OperatorCode != "26201" {
+		t.Errorf("Expected operator code 26201, got %s", mm.Modem.ThreeGPP.OperatorCode)
+	}
+	if mm.Unknown != nil {
+		t.Errorf("Expected the fixture to be fully modelled, got unknown values %s", mm.Unknown)
+	}
+
+	sim, err := client.GetSIMInfo(ctx, "0")
+	if err != nil {
+		t.Fatalf("Failed to get SIM info: %v", err)
+	}
+	if sim.Properties.ICCID != "89490200001234567890" {
+		t.Errorf("Expected ICCID 89490200001234567890, got %s", sim.Properties.ICCID)
+	}
+
+	status, err := client.GetLocationStatus(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to get location status: %v", err)
+	}
+	if len(status.Enabled) != 3 {
+		t.Errorf("Expected 3 enabled location sources, got %v", status.Enabled)
+	}
+
+	location, err := client.GetLocation(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to get location: %v", err)
+	}
+	if location.GPS.Latitude != "52.520008" || len(location.GPS.NMEA) != 4 {
+		t.Errorf("Unexpected GPS location %+v", location.GPS)
+	}
+	if location.ThreeGPP.MCC != "262" {
+		t.Errorf("Expected MCC 262, got %s", location.ThreeGPP.MCC)
+	}
+
+	messaging, err := client.GetMessagingStatus(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to get messaging status: %v", err)
+	}
+	if len(messaging.SupportedStorages) != 2 {
+		t.Errorf("Expected 2 supported storages, got %v", messaging.SupportedStorages)
+	}
+
+	messages, err := client.ListSMS(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to list SMS: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Errorf("Expected 2 messages, got %v", messages)
+	}
+
+	sms, err := client.GetSMSInfo(ctx, "0")
+	if err != nil {
+		t.Fatalf("Failed to get SMS info: %v", err)
+	}
+	if sms.Properties.Text != "Status?" {
+		t.Errorf("Expected text Status?, got %s", sms.Properties.Text)
+	}
+
+	networkTime, err := client.GetNetworkTimeAsTime(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to get network time: %v", err)
+	}
+	if !networkTime.Equal(time.Date(2024, 3, 15, 10, 15, 30, 0, time.UTC)) {
+		t.Errorf("Unexpected network time %v", networkTime)
+	}
+}
+
+func TestOverrideAndMatchers(t *testing.T) {
+	r := NewRunner(t)
+	r.On("-m", "0", "--time", "-J").Exit(1).Stderr("error: couldn't get network time: 'GDBus.Error:org.freedesktop.ModemManager1.Error.Core.WrongState: modem not registered'").Once()
+	r.OnFixtures()
+	r.On("-m", "0", Prefix("--simple-connect=")).Once()
+
+	client := r.Client()
+	ctx := context.Background()
+
+	_, err := client.GetNetworkTime(ctx, "0")
+	if !errors.Is(err, mmcli.ErrWrongState) {
+		t.Errorf("Expected ErrWrongState, got %v", err)
+	}
+
+	// the override is used up, so the fixture answers now
+	if _, err := client.GetNetworkTime(ctx, "0"); err != nil {
+		t.Errorf("Failed to get network time: %v", err)
+	}
+
+	if err := client.Connect(ctx, "0", mmcli.ConnectSettings{APN: "internet"}); err != nil {
+		t.Errorf("Failed to connect: %v", err)
+	}
+
+	r.AssertCalled(t, "-m", "0", `--simple-connect="apn=internet"`)
+	r.AssertNotCalled(t, "-m", "0", "--simple-disconnect")
+}
+
+func TestIgnoresTimeout(t *testing.T) {
+	r := NewRunner(t).OnFixtures()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if _, err := r.Client().ListModems(ctx); err != nil {
+		t.Fatalf("Failed to list modems: %v", err)
+	}
+
+	calls := r.Calls()
+	if len(calls) != 1 || !strings.HasPrefix(calls[0][len(calls[0])-1], "--timeout=") {
+		t.Errorf("Expected a call with --timeout, got %v", calls)
+	}
+	r.AssertCalled(t, "-J", "-L")
+}
+
+func TestStream(t *testing.T) {
+	r := NewRunner(t).OnFixtures()
+	r.On("-M").Return("(+) /org/freedesktop/ModemManager1/Modem/0 [Quectel] EC25\n").KeepOpen()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := r.Client().WatchModems(ctx)
+	if err != nil {
+		t.Fatalf("Failed to watch modems: %v", err)
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != mmcli.ModemAdded || event.Index != 0 {
+			t.Errorf("Expected modem 0 to be added, got %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for an event")
+	}
+
+	cancel()
+	for range events {
+	}
+	r.AssertCalled(t, "-M")
+}
+
+func TestUnexpectedAndUnmet(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	r := &Runner{t: tb}
+	r.On("-m", "0", "--reset").Once()
+	r.On("-m", "0", "--simple-disconnect").Times(2)
+	r.On("-m", "0", "--enable").Maybe()
+
+	err := r.Client().Disconnect(context.Background(), "0")
+	if err != nil {
+		t.Fatalf("Failed to disconnect: %v", err)
+	}
+
+	_, err = r.Client().ListModems(context.Background())
+	var mmErr *mmcli.Error
+	if !errors.As(err, &mmErr) {
+		t.Errorf("Expected *mmcli.Error for an unexpected invocation, got %v", err)
+	}
+
+	r.AssertExpectations(tb)
+
+	expected := []string{
+		"mmclitest: unexpected invocation: mmcli -J -L",
+		"mmclitest: expected mmcli -m 0 --reset 1 times, got 0",
+		"mmclitest: expected mmcli -m 0 --simple-disconnect 2 times, got 1",
+	}
+	if strings.Join(tb.errors, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected errors %q, got %q", expected, tb.errors)
+	}
+}
+
+func TestFail(t *testing.T) {
+	r := NewRunner(t)
+	r.On("-J", "-L").Fail(errors.New("exec: \"mmcli\": executable file not found in $PATH"))
+
+	if _, err := r.Client().ListModems(context.Background()); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestInstall(t *testing.T) {
+	r := NewRunner(t).OnFixtures()
+	r.On("-m", "0", "--simple-disconnect").Exit(1).Stderr("error: couldn't find modem").Once()
+	r.Install(t)
+
+	client := mmcli.NewClient(nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	mm, err := client.GetModemDetails(ctx, "0")
+	if err != nil {
+		t.Fatalf("Failed to get modem details: %v", err)
+	}
+	if mm.Modem.Generic.Model != "EC25" {
+		t.Errorf("Expected model EC25, got %s", mm.Modem.Generic.Model)
+	}
+
+	if err := client.Disconnect(ctx, "0"); !errors.Is(err, mmcli.ErrModemNotFound) {
+		t.Errorf("Expected ErrModemNotFound, got %v", err)
+	}
+
+	r.AssertCalled(t, "-m", "0", "-J")
+	r.AssertCalled(t, "-m", "0", "--simple-disconnect")
+}
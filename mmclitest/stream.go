@@ -0,0 +1,59 @@
+package mmclitest
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/rescoot/go-mmcli"
+)
+
+var _ mmcli.StreamRunner = (*Runner)(nil)
+
+// Stream answers a long-lived invocation such as mmcli -M from the first
+// matching expectation. The stream yields the expectation's standard output
+// and then ends as if mmcli exited, unless the expectation is kept open with
+// KeepOpen. A non-zero exit code is reported when the stream is closed.
+func (r *Runner) Stream(ctx context.Context, args ...string) (io.ReadCloser, error) {
+	res, open, err := r.answer(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &stream{result: res, closed: make(chan struct{})}
+	s.Reader = strings.NewReader(string(res.Stdout))
+	if open {
+		s.Reader = io.MultiReader(s.Reader, &blockingReader{ctx: ctx, closed: s.closed})
+	}
+	return s, nil
+}
+
+type stream struct {
+	io.Reader
+	result    *mmcli.Result
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *stream) Close() error {
+	s.closeOnce.Do(func() { close(s.closed) })
+	if s.result.ExitCode != 0 {
+		return &mmcli.StreamExitError{ExitCode: s.result.ExitCode, Stderr: s.result.Stderr}
+	}
+	return nil
+}
+
+// blockingReader blocks until the stream is closed or the context is cancelled
+type blockingReader struct {
+	ctx    context.Context
+	closed <-chan struct{}
+}
+
+func (b *blockingReader) Read(p []byte) (int, error) {
+	select {
+	case <-b.ctx.Done():
+	case <-b.closed:
+	}
+	return 0, io.EOF
+}
@@ -0,0 +1,262 @@
+package mmdbus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rescoot/go-mmcli"
+)
+
+// The tables below map ModemManager enum and flag values to the nicknames
+// mmcli prints, so that both backends fill the mmcli structs identically.
+
+var capabilityFlags = []flagNick{
+	{1 << 0, "pots"},
+	{1 << 1, "cdma-evdo"},
+	{1 << 2, "gsm-umts"},
+	{1 << 3, "lte"},
+	{1 << 5, "iridium"},
+	{1 << 6, "5gnr"},
+	{1 << 7, "tds"},
+}
+
+var ipFamilyFlags = []flagNick{
+	{1 << 0, "ipv4"},
+	{1 << 1, "ipv6"},
+	{1 << 2, "ipv4v6"},
+	{1 << 3, "non-ip"},
+}
+
+var facilityLockFlags = []flagNick{
+	{1 << 0, "sim"},
+	{1 << 1, "fixed-dialing"},
+	{1 << 2, "ph-sim"},
+	{1 << 3, "ph-fsim"},
+	{1 << 4, "net-pers"},
+	{1 << 5, "net-sub-pers"},
+	{1 << 6, "provider-pers"},
+	{1 << 7, "corp-pers"},
+}
+
+var locationSourceFlags = []flagNick{
+	{1 << 0, "3gpp-lac-ci"},
+	{1 << 1, "gps-raw"},
+	{1 << 2, "gps-nmea"},
+	{1 << 3, "cdma-bs"},
+	{1 << 4, "gps-unmanaged"},
+	{1 << 5, "agps-msa"},
+	{1 << 6, "agps-msb"},
+}
+
+var assistanceDataFlags = []flagNick{
+	{1 << 0, "xtra"},
+}
+
+var powerStateNicks = map[uint32]string{
+	0: "unknown",
+	1: "off",
+	2: "low",
+	3: "on",
+}
+
+var registrationStateNicks = map[uint32]string{
+	0:  "idle",
+	1:  "home",
+	2:  "searching",
+	3:  "denied",
+	4:  "unknown",
+	5:  "roaming",
+	6:  "home-sms-only",
+	7:  "roaming-sms-only",
+	8:  "emergency-only",
+	9:  "home-csfb-not-preferred",
+	10: "roaming-csfb-not-preferred",
+	11: "attached-rlos",
+}
+
+var epsUeModeNicks = map[uint32]string{
+	0: "unknown",
+	1: "ps-1",
+	2: "ps-2",
+	3: "csps-1",
+	4: "csps-2",
+}
+
+var packetServiceStateNicks = map[uint32]string{
+	0: "unknown",
+	1: "detached",
+	2: "attached",
+}
+
+var micoModeNicks = map[uint32]string{
+	0: "unknown",
+	1: "unsupported",
+	2: "disabled",
+	3: "enabled",
+}
+
+var drxCycleNicks = map[uint32]string{
+	0: "unknown",
+	1: "unsupported",
+	2: "32",
+	3: "64",
+	4: "128",
+	5: "256",
+}
+
+var cdmaActivationStateNicks = map[uint32]string{
+	0: "unknown",
+	1: "not-activated",
+	2: "activating",
+	3: "partially-activated",
+	4: "activated",
+}
+
+var cdmaRegistrationStateNicks = map[uint32]string{
+	0: "unknown",
+	1: "registered",
+	2: "home",
+	3: "roaming",
+}
+
+var smsStorageNicks = map[uint32]string{
+	0: "unknown",
+	1: "sm",
+	2: "me",
+	3: "mt",
+	4: "sr",
+	5: "bm",
+	6: "ta",
+}
+
+var smsStateNicks = map[uint32]string{
+	0: "unknown",
+	1: "stored",
+	2: "receiving",
+	3: "received",
+	4: "sending",
+	5: "sent",
+}
+
+var smsPduTypeNicks = map[uint32]string{
+	0:  "unknown",
+	1:  "deliver",
+	2:  "submit",
+	3:  "status-report",
+	32: "cdma-deliver",
+	33: "cdma-submit",
+	34: "cdma-cancellation",
+	35: "cdma-delivery-acknowledgement",
+	36: "cdma-user-acknowledgement",
+	37: "cdma-read-acknowledgement",
+}
+
+var smsDeliveryStateNicks = map[uint32]string{
+	0x00:  "completed-received",
+	0x01:  "completed-forwarded-unconfirmed",
+	0x02:  "completed-replaced-by-sc",
+	0x20:  "temporary-error-congestion",
+	0x21:  "temporary-error-sme-busy",
+	0x22:  "temporary-error-no-response-from-sme",
+	0x23:  "temporary-error-service-rejected",
+	0x24:  "temporary-error-qos-not-available",
+	0x25:  "temporary-error-in-sme",
+	0x40:  "error-remote-procedure",
+	0x41:  "error-incompatible-destination",
+	0x42:  "error-connection-rejected-by-sme",
+	0x43:  "error-not-obtainable",
+	0x44:  "error-qos-not-available",
+	0x45:  "error-no-interworking-available",
+	0x46:  "error-validity-period-expired",
+	0x47:  "error-deleted-by-originating-sme",
+	0x48:  "error-deleted-by-sc-administration",
+	0x49:  "error-message-does-not-exist",
+	0x60:  "temporary-fatal-error-congestion",
+	0x61:  "temporary-fatal-error-sme-busy",
+	0x62:  "temporary-fatal-error-no-response-from-sme",
+	0x63:  "temporary-fatal-error-service-rejected",
+	0x64:  "temporary-fatal-error-qos-not-available",
+	0x65:  "temporary-fatal-error-in-sme",
+	0x100: "unknown",
+}
+
+var smsTeleserviceNicks = map[uint32]string{
+	0x0000: "unknown",
+	0x1000: "cmt-91",
+	0x1001: "wpt",
+	0x1002: "wmt",
+	0x1003: "vmn",
+	0x1004: "wap",
+	0x1005: "wemt",
+	0x1006: "scpt",
+	0x1007: "catpt",
+}
+
+// flagNick associates a single flag bit with its nickname
+type flagNick struct {
+	bit  uint32
+	nick string
+}
+
+// flagNicks returns the nicknames of all bits set in value, in bit order
+func flagNicks(value uint32, flags []flagNick) []string {
+	var nicks []string
+	for _, f := range flags {
+		if value&f.bit != 0 {
+			nicks = append(nicks, f.nick)
+		}
+	}
+	return nicks
+}
+
+// flagBit returns the flag bit with the given nickname
+func flagBit(nick string, flags []flagNick) (uint32, bool) {
+	for _, f := range flags {
+		if f.nick == nick {
+			return f.bit, true
+		}
+	}
+	return 0, false
+}
+
+// nick returns the nickname of an enum value, or "unknown" for values missing from the table
+func nick[K comparable](table map[K]string, value K) string {
+	if n, ok := table[value]; ok {
+		return n
+	}
+	return "unknown"
+}
+
+// nickValue returns the enum value with the given nickname
+func nickValue(table map[uint32]string, n string) (uint32, bool) {
+	for value, candidate := range table {
+		if candidate == n {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// capabilitiesString formats a capability mask the way mmcli does, e.g. "gsm-umts, lte"
+func capabilitiesString(value uint32) string {
+	if value == 0 {
+		return "none"
+	}
+	return strings.Join(flagNicks(value, capabilityFlags), ", ")
+}
+
+// unlockRetriesStrings formats unlock retries the way mmcli does, e.g. "sim-pin (3)"
+func unlockRetriesStrings(retries map[uint32]uint32) []string {
+	locks := make([]uint32, 0, len(retries))
+	for lock := range retries {
+		locks = append(locks, lock)
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i] < locks[j] })
+
+	out := make([]string, 0, len(locks))
+	for _, lock := range locks {
+		out = append(out, fmt.Sprintf("%s (%d)", mmcli.Lock(lock).String(), retries[lock]))
+	}
+	return out
+}
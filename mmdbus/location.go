@@ -0,0 +1,171 @@
+package mmdbus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/rescoot/go-mmcli"
+)
+
+// Location source bits as used by the Location interface
+const (
+	locationSource3GPP = 1 << 0
+	locationSourceRaw  = 1 << 1
+	locationSourceNMEA = 1 << 2
+	locationSourceCDMA = 1 << 3
+)
+
+// locationMethods maps the method names accepted by EnableLocationGathering to location source bits
+var locationMethods = map[string]uint32{
+	"3gpp":          1 << 0,
+	"gps-raw":       1 << 1,
+	"gps-nmea":      1 << 2,
+	"cdma-bs":       1 << 3,
+	"gps-unmanaged": 1 << 4,
+	"agps-msa":      1 << 5,
+	"agps-msb":      1 << 6,
+}
+
+// GetLocationStatus returns the current status of location gathering
+func (b *Backend) GetLocationStatus(ctx context.Context, modemID string) (*mmcli.LocationStatus, error) {
+	path, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get location status: %w", err)
+	}
+
+	props, err := b.getAll(ctx, path, ifaceLocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get location status: %w", err)
+	}
+
+	status := &mmcli.LocationStatus{
+		Capabilities: flagNicks(props.u32("Capabilities"), locationSourceFlags),
+		Enabled:      flagNicks(props.u32("Enabled"), locationSourceFlags),
+		Signals:      yesNo(props.bool("SignalsLocation")),
+		GPS: mmcli.GPSInfo{
+			Assistance:        flagNicks(props.u32("SupportedAssistanceData"), assistanceDataFlags),
+			AssistanceServers: props.strs("AssistanceDataServers"),
+			RefreshRate:       strconv.FormatUint(uint64(props.u32("GpsRefreshRate")), 10),
+			SuplServer:        props.str("SuplServer"),
+		},
+	}
+	status.Normalize()
+	return status, nil
+}
+
+// GetLocation returns the current location information
+func (b *Backend) GetLocation(ctx context.Context, modemID string) (*mmcli.LocationInfo, error) {
+	path, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get location: %w", err)
+	}
+
+	var location map[uint32]dbus.Variant
+	if err := b.call(ctx, path, ifaceLocation+".GetLocation").Store(&location); err != nil {
+		return nil, fmt.Errorf("failed to get location: %w", convertError(err))
+	}
+
+	var info mmcli.LocationInfo
+
+	// 3GPP location is a string "MCC,MNC,LAC,CI,TAC" with hexadecimal LAC, CI and TAC
+	if v, ok := location[locationSource3GPP]; ok {
+		s, _ := v.Value().(string)
+		parts := strings.Split(s, ",")
+		for len(parts) < 5 {
+			parts = append(parts, "")
+		}
+		info.ThreeGPP = mmcli.ThreeGPPLocation{
+			MCC: parts[0],
+			MNC: parts[1],
+			LAC: parts[2],
+			CID: parts[3],
+			TAC: parts[4],
+		}
+	}
+
+	if v, ok := location[locationSourceRaw]; ok {
+		raw, _ := v.Value().(map[string]dbus.Variant)
+		props := properties(raw)
+		info.GPS.UTC = props.str("utc-time")
+		info.GPS.Latitude = floatString(props, "latitude")
+		info.GPS.Longitude = floatString(props, "longitude")
+		info.GPS.Altitude = floatString(props, "altitude")
+	}
+
+	if v, ok := location[locationSourceNMEA]; ok {
+		s, _ := v.Value().(string)
+		for _, trace := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+			if trace != "" {
+				info.GPS.NMEA = append(info.GPS.NMEA, trace)
+			}
+		}
+	}
+
+	if v, ok := location[locationSourceCDMA]; ok {
+		raw, _ := v.Value().(map[string]dbus.Variant)
+		props := properties(raw)
+		info.CDMABS.Latitude = floatString(props, "latitude")
+		info.CDMABS.Longitude = floatString(props, "longitude")
+	}
+
+	info.Normalize()
+	return &info, nil
+}
+
+// floatString formats a floating point property, or returns an empty string if it is missing
+func floatString(p properties, name string) string {
+	f, ok := p.value(name).(float64)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// EnableLocationGathering enables a specific location gathering method
+func (b *Backend) EnableLocationGathering(ctx context.Context, modemID string, method string) error {
+	if err := b.setupLocation(ctx, modemID, method, true); err != nil {
+		return fmt.Errorf("failed to enable location gathering (%s): %w", method, err)
+	}
+	return nil
+}
+
+// DisableLocationGathering disables a specific location gathering method
+func (b *Backend) DisableLocationGathering(ctx context.Context, modemID string, method string) error {
+	if err := b.setupLocation(ctx, modemID, method, false); err != nil {
+		return fmt.Errorf("failed to disable location gathering (%s): %w", method, err)
+	}
+	return nil
+}
+
+// setupLocation toggles one location source while keeping the others and the signaling setting
+func (b *Backend) setupLocation(ctx context.Context, modemID string, method string, enable bool) error {
+	source, ok := locationMethods[method]
+	if !ok {
+		return fmt.Errorf("unsupported location method: %s", method)
+	}
+
+	path, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return err
+	}
+
+	props, err := b.getAll(ctx, path, ifaceLocation)
+	if err != nil {
+		return err
+	}
+
+	sources := props.u32("Enabled")
+	if enable {
+		sources |= source
+	} else {
+		sources &^= source
+	}
+
+	if err := b.call(ctx, path, ifaceLocation+".Setup", sources, props.bool("SignalsLocation")).Err; err != nil {
+		return convertError(err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package mmdbus
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/rescoot/go-mmcli"
+)
+
+// GetMessagingStatus returns the status of messaging support
+func (b *Backend) GetMessagingStatus(ctx context.Context, modemID string) (*mmcli.MessagingStatus, error) {
+	modemPath, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messaging status: %w", err)
+	}
+
+	props, err := b.getAll(ctx, modemPath, ifaceMessaging)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messaging status: %w", err)
+	}
+
+	status := &mmcli.MessagingStatus{
+		DefaultStorages:   []string{nick(smsStorageNicks, props.u32("DefaultStorage"))},
+		SupportedStorages: []string{},
+	}
+	for _, storage := range props.u32s("SupportedStorages") {
+		status.SupportedStorages = append(status.SupportedStorages, nick(smsStorageNicks, storage))
+	}
+
+	return status, nil
+}
+
+// ListSMS returns the D-Bus paths of the SMS messages on a modem
+func (b *Backend) ListSMS(ctx context.Context, modemID string) ([]string, error) {
+	modemPath, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SMS messages: %w", err)
+	}
+
+	var messages []dbus.ObjectPath
+	if err := b.call(ctx, modemPath, ifaceMessaging+".List").Store(&messages); err != nil {
+		return nil, fmt.Errorf("failed to list SMS messages: %w", convertError(err))
+	}
+
+	list := make([]string, 0, len(messages))
+	for _, m := range messages {
+		list = append(list, string(m))
+	}
+	return list, nil
+}
+
+// GetSMSInfo returns information about a specific SMS message
+func (b *Backend) GetSMSInfo(ctx context.Context, smsID string) (*mmcli.SMSInfo, error) {
+	smsPath := objectPath(smsPathPrefix, smsID)
+	props, err := b.getAll(ctx, smsPath, ifaceSms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SMS info: %w", err)
+	}
+
+	var validity string
+	if v := props.fields("Validity"); field[uint32](v, 0) != 0 {
+		if variant, ok := field[dbus.Variant](v, 1).Value().(uint32); ok {
+			validity = strconv.FormatUint(uint64(variant), 10)
+		}
+	}
+
+	sms := &mmcli.SMSInfo{
+		DBusPath: string(smsPath),
+		Properties: mmcli.SMSProperties{
+			Class:              int(props.i32("Class")),
+			DeliveryReportReq:  props.bool("DeliveryReportRequest"),
+			DeliveryState:      nickIf(props, "DeliveryState", smsDeliveryStateNicks),
+			DischargeTimestamp: props.str("DischargeTimestamp"),
+			Number:             props.str("Number"),
+			PDU:                nickIf(props, "PduType", smsPduTypeNicks),
+			SMSC:               props.str("SMSC"),
+			State:              nickIf(props, "State", smsStateNicks),
+			Storage:            nickIf(props, "Storage", smsStorageNicks),
+			Teleservice:        nickIf(props, "TeleserviceId", smsTeleserviceNicks),
+			Text:               props.str("Text"),
+			Timestamp:          props.str("Timestamp"),
+			Validity:           validity,
+			Data:               byteSlice(props, "Data"),
+		},
+	}
+	sms.Normalize()
+	return sms, nil
+}
+
+// byteSlice returns a byte array property
+func byteSlice(p properties, name string) []byte {
+	data, _ := p.value(name).([]byte)
+	return data
+}
+
+// CreateSMS creates a new SMS message and returns its ID
+func (b *Backend) CreateSMS(ctx context.Context, modemID string, settings mmcli.SMSCreateSettings) (string, error) {
+	modemPath, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create SMS: %w", err)
+	}
+
+	props := map[string]dbus.Variant{}
+	if settings.Number != "" {
+		props["number"] = dbus.MakeVariant(settings.Number)
+	}
+	if settings.Text != "" {
+		props["text"] = dbus.MakeVariant(settings.Text)
+	}
+	if settings.SMSC != "" {
+		props["smsc"] = dbus.MakeVariant(settings.SMSC)
+	}
+	if settings.Validity != "" {
+		validity, err := strconv.ParseUint(settings.Validity, 10, 32)
+		if err != nil {
+			return "", fmt.Errorf("failed to create SMS: invalid validity %q: %w", settings.Validity, err)
+		}
+		props["validity"] = dbus.MakeVariant(uint32(validity))
+	}
+	if settings.Class != 0 {
+		props["class"] = dbus.MakeVariant(int32(settings.Class))
+	}
+	if settings.DeliveryReportReq {
+		props["delivery-report-request"] = dbus.MakeVariant(true)
+	}
+
+	var smsPath dbus.ObjectPath
+	if err := b.call(ctx, modemPath, ifaceMessaging+".Create", props).Store(&smsPath); err != nil {
+		return "", fmt.Errorf("failed to create SMS: %w", convertError(err))
+	}
+
+	return path.Base(string(smsPath)), nil
+}
+
+// SendSMS sends an SMS message
+func (b *Backend) SendSMS(ctx context.Context, smsID string) error {
+	if err := b.call(ctx, objectPath(smsPathPrefix, smsID), ifaceSms+".Send").Err; err != nil {
+		return fmt.Errorf("failed to send SMS: %w", convertError(err))
+	}
+	return nil
+}
+
+// DeleteSMS deletes an SMS message
+func (b *Backend) DeleteSMS(ctx context.Context, modemID string, smsID string) error {
+	modemPath, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return fmt.Errorf("failed to delete SMS: %w", err)
+	}
+
+	if err := b.call(ctx, modemPath, ifaceMessaging+".Delete", objectPath(smsPathPrefix, smsID)).Err; err != nil {
+		return fmt.Errorf("failed to delete SMS: %w", convertError(err))
+	}
+	return nil
+}
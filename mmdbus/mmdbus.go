@@ -0,0 +1,317 @@
+// Package mmdbus implements mmcli.Backend by talking to ModemManager over
+// D-Bus directly instead of spawning mmcli for every operation.
+package mmdbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/rescoot/go-mmcli"
+)
+
+const (
+	serviceName = "org.freedesktop.ModemManager1"
+	rootPath    = dbus.ObjectPath("/org/freedesktop/ModemManager1")
+
+	ifaceModem     = serviceName + ".Modem"
+	iface3gpp      = serviceName + ".Modem.Modem3gpp"
+	ifaceCdma      = serviceName + ".Modem.ModemCdma"
+	ifaceSimple    = serviceName + ".Modem.Simple"
+	ifaceLocation  = serviceName + ".Modem.Location"
+	ifaceMessaging = serviceName + ".Modem.Messaging"
+	ifaceTime      = serviceName + ".Modem.Time"
+	ifaceSignal    = serviceName + ".Modem.Signal"
+	ifaceSim       = serviceName + ".Sim"
+	ifaceSms       = serviceName + ".Sms"
+
+	ifaceProperties    = "org.freedesktop.DBus.Properties"
+	ifaceObjectManager = "org.freedesktop.DBus.ObjectManager"
+
+	simPathPrefix = "/org/freedesktop/ModemManager1/SIM/"
+	smsPathPrefix = "/org/freedesktop/ModemManager1/SMS/"
+)
+
+// Backend talks to ModemManager on a D-Bus connection
+type Backend struct {
+	conn *dbus.Conn
+}
+
+var _ mmcli.Backend = (*Backend)(nil)
+
+// New returns a Backend using an established D-Bus connection
+func New(conn *dbus.Conn) *Backend {
+	return &Backend{conn: conn}
+}
+
+// NewSystem connects to the system bus, where ModemManager normally runs
+func NewSystem() (*Backend, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+	return New(conn), nil
+}
+
+// Close closes the underlying D-Bus connection
+func (b *Backend) Close() error {
+	return b.conn.Close()
+}
+
+// Modem returns a handle for the modem matching the given selector
+// (D-Bus path, index, device UID or "any")
+func (b *Backend) Modem(selector string) *mmcli.ModemHandle {
+	return mmcli.NewModemHandle(b, selector)
+}
+
+// call invokes a method on a ModemManager object
+func (b *Backend) call(ctx context.Context, path dbus.ObjectPath, method string, args ...interface{}) *dbus.Call {
+	return b.conn.Object(serviceName, path).CallWithContext(ctx, method, 0, args...)
+}
+
+// getAll returns all properties of an interface on a ModemManager object
+func (b *Backend) getAll(ctx context.Context, path dbus.ObjectPath, iface string) (properties, error) {
+	var props map[string]dbus.Variant
+	if err := b.call(ctx, path, ifaceProperties+".GetAll", iface).Store(&props); err != nil {
+		return nil, convertError(err)
+	}
+	return props, nil
+}
+
+// managedObjects returns all modems with their interfaces and properties
+func (b *Backend) managedObjects(ctx context.Context) (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, error) {
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	if err := b.call(ctx, rootPath, ifaceObjectManager+".GetManagedObjects").Store(&objects); err != nil {
+		return nil, convertError(err)
+	}
+	return objects, nil
+}
+
+// convertError turns D-Bus error replies into *mmcli.Error so that the
+// mmcli sentinel errors work with this backend too
+func convertError(err error) error {
+	var dbusErr dbus.Error
+	if errors.As(err, &dbusErr) {
+		var message string
+		if len(dbusErr.Body) > 0 {
+			message, _ = dbusErr.Body[0].(string)
+		}
+		return mmcli.NewDBusError(dbusErr.Name, message)
+	}
+	var dbusErrPtr *dbus.Error
+	if errors.As(err, &dbusErrPtr) {
+		return convertError(*dbusErrPtr)
+	}
+	return err
+}
+
+// ListModems returns the D-Bus paths of all available modems
+func (b *Backend) ListModems(ctx context.Context) ([]string, error) {
+	objects, err := b.managedObjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modems: %w", err)
+	}
+
+	return modemPaths(objects), nil
+}
+
+// modemPaths returns the paths of all objects implementing the modem interface, ordered by index
+func modemPaths(objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant) []string {
+	paths := make([]string, 0, len(objects))
+	for path, ifaces := range objects {
+		if _, ok := ifaces[ifaceModem]; ok {
+			paths = append(paths, string(path))
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool { return lessModemPath(paths[i], paths[j]) })
+	return paths
+}
+
+// lessModemPath orders modem paths by index
+func lessModemPath(a, b string) bool {
+	ia, erra := mmcli.ModemIndexFromPath(a)
+	ib, errb := mmcli.ModemIndexFromPath(b)
+	if erra != nil || errb != nil {
+		return a < b
+	}
+	return ia < ib
+}
+
+// findModem resolves a modem selector the way mmcli -m does and returns the
+// modem path together with its interfaces and properties
+func (b *Backend) findModem(ctx context.Context, modemID string) (dbus.ObjectPath, map[string]map[string]dbus.Variant, error) {
+	objects, err := b.managedObjects(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, p := range modemPaths(objects) {
+		path := dbus.ObjectPath(p)
+		ifaces := objects[path]
+		switch {
+		case modemID == "any",
+			modemID == p,
+			mmcli.ModemPathPrefix+modemID == p,
+			properties(ifaces[ifaceModem]).str("Device") == modemID:
+			return path, ifaces, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("couldn't find modem %s: %w", modemID, mmcli.ErrModemNotFound)
+}
+
+// objectPath turns a SIM or SMS selector (path or index) into an object path
+func objectPath(prefix, id string) dbus.ObjectPath {
+	if strings.HasPrefix(id, "/") {
+		return dbus.ObjectPath(id)
+	}
+	return dbus.ObjectPath(prefix + id)
+}
+
+// GetModemDetails returns details for a specific modem
+func (b *Backend) GetModemDetails(ctx context.Context, modemID string) (*mmcli.ModemManager, error) {
+	path, ifaces, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get modem details: %w", err)
+	}
+
+	mm := &mmcli.ModemManager{Modem: modemFromProperties(path, ifaces)}
+	mm.Normalize()
+	return mm, nil
+}
+
+// ResetModem resets a modem
+func (b *Backend) ResetModem(ctx context.Context, modemID string) (bool, error) {
+	path, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return false, fmt.Errorf("failed to reset modem: %w", err)
+	}
+
+	if err := b.call(ctx, path, ifaceModem+".Reset").Err; err != nil {
+		return false, fmt.Errorf("failed to reset modem: %w", convertError(err))
+	}
+
+	return true, nil
+}
+
+// GetSIMInfo returns information about a SIM card
+func (b *Backend) GetSIMInfo(ctx context.Context, simID string) (*mmcli.SIMInfo, error) {
+	path := objectPath(simPathPrefix, simID)
+	props, err := b.getAll(ctx, path, ifaceSim)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SIM info: %w", err)
+	}
+
+	sim := &mmcli.SIMInfo{
+		DBusPath: string(path),
+		Properties: mmcli.SIMProperties{
+			Active:           yesNo(props.bool("Active")),
+			EID:              props.str("Eid"),
+			EmergencyNumbers: props.strs("EmergencyNumbers"),
+			ICCID:            props.str("SimIdentifier"),
+			IMSI:             props.str("Imsi"),
+			OperatorCode:     props.str("OperatorIdentifier"),
+			OperatorName:     props.str("OperatorName"),
+		},
+	}
+	sim.Normalize()
+	return sim, nil
+}
+
+// Connect establishes a connection with the specified settings
+func (b *Backend) Connect(ctx context.Context, modemID string, settings mmcli.ConnectSettings) error {
+	path, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	props := map[string]dbus.Variant{}
+	if settings.APN != "" {
+		props["apn"] = dbus.MakeVariant(settings.APN)
+	}
+	if settings.User != "" {
+		props["user"] = dbus.MakeVariant(settings.User)
+	}
+	if settings.Password != "" {
+		props["password"] = dbus.MakeVariant(settings.Password)
+	}
+	if settings.IPType != "" {
+		ipType, ok := flagBit(settings.IPType, ipFamilyFlags)
+		if !ok {
+			return fmt.Errorf("failed to connect: unsupported IP type: %s", settings.IPType)
+		}
+		props["ip-type"] = dbus.MakeVariant(ipType)
+	}
+	if settings.Number != "" {
+		props["number"] = dbus.MakeVariant(settings.Number)
+	}
+	if settings.AllowRoaming {
+		props["allow-roaming"] = dbus.MakeVariant(true)
+	}
+	if settings.PIN != "" {
+		props["pin"] = dbus.MakeVariant(settings.PIN)
+	}
+
+	if err := b.call(ctx, path, ifaceSimple+".Connect", props).Err; err != nil {
+		return fmt.Errorf("failed to connect: %w", convertError(err))
+	}
+
+	return nil
+}
+
+// Disconnect disconnects all connected bearers
+func (b *Backend) Disconnect(ctx context.Context, modemID string) error {
+	path, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return fmt.Errorf("failed to disconnect: %w", err)
+	}
+
+	if err := b.call(ctx, path, ifaceSimple+".Disconnect", dbus.ObjectPath("/")).Err; err != nil {
+		return fmt.Errorf("failed to disconnect: %w", convertError(err))
+	}
+
+	return nil
+}
+
+// GetNetworkTime returns the current network time
+func (b *Backend) GetNetworkTime(ctx context.Context, modemID string) (*mmcli.TimeInfo, error) {
+	path, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network time: %w", err)
+	}
+
+	var networkTime string
+	if err := b.call(ctx, path, ifaceTime+".GetNetworkTime").Store(&networkTime); err != nil {
+		return nil, fmt.Errorf("failed to get network time: %w", convertError(err))
+	}
+
+	return &mmcli.TimeInfo{NetworkTime: networkTime}, nil
+}
+
+// yesNo formats a boolean the way mmcli does
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+// optionalPath returns the path as a string, or an empty string for the "/" placeholder
+func optionalPath(path dbus.ObjectPath) string {
+	if path == "/" {
+		return ""
+	}
+	return string(path)
+}
+
+// uintString formats a number, or returns an empty string for zero
+func uintString(v uint32) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(v), 10)
+}
@@ -0,0 +1,623 @@
+package mmdbus
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	"github.com/rescoot/go-mmcli"
+)
+
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=SOCKET</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*"/>
+    <allow receive_sender="*"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// startBus starts a private dbus-daemon and returns its address
+func startBus(t *testing.T) string {
+	t.Helper()
+
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not available, skipping test")
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(config, []byte(strings.Replace(busConfig, "SOCKET", filepath.Join(dir, "bus"), 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skip("Could not start dbus-daemon:", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+// mockModemManager exports a ModemManager service with one modem, one SIM and one SMS
+type mockModemManager struct {
+	mu    sync.Mutex
+	calls []string
+
+	locationEnabled uint32
+}
+
+func (m *mockModemManager) record(call string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+const (
+	modemPath = dbus.ObjectPath("/org/freedesktop/ModemManager1/Modem/3")
+	simPath   = dbus.ObjectPath("/org/freedesktop/ModemManager1/SIM/2")
+	smsPath   = dbus.ObjectPath("/org/freedesktop/ModemManager1/SMS/5")
+)
+
+func (m *mockModemManager) modemInterfaces() map[string]map[string]dbus.Variant {
+	return map[string]map[string]dbus.Variant{
+		ifaceModem: {
+			"Sim":                   dbus.MakeVariant(simPath),
+			"Bearers":               dbus.MakeVariant([]dbus.ObjectPath{"/org/freedesktop/ModemManager1/Bearer/0"}),
+			"SupportedCapabilities": dbus.MakeVariant([]uint32{4 | 8}),
+			"CurrentCapabilities":   dbus.MakeVariant(uint32(4 | 8)),
+			"Manufacturer":          dbus.MakeVariant("QUALCOMM INCORPORATED"),
+			"Model":                 dbus.MakeVariant("SIMCOM_SIM7100E"),
+			"Revision":              dbus.MakeVariant("4534B03SIM7100E"),
+			"Device":                dbus.MakeVariant("/sys/devices/platform/soc/usb1/1-1"),
+			"Drivers":               dbus.MakeVariant([]string{"option", "qmi_wwan"}),
+			"Plugin":                dbus.MakeVariant("simtech"),
+			"PrimaryPort":           dbus.MakeVariant("cdc-wdm0"),
+			"Ports": dbus.MakeVariant([]struct {
+				Name string
+				Type uint32
+			}{{"cdc-wdm0", 6}, {"ttyUSB2", 3}, {"ttyUSB3", 3}, {"wwan0", 2}}),
+			"EquipmentIdentifier": dbus.MakeVariant("123456789012345"),
+			"UnlockRequired":      dbus.MakeVariant(uint32(1)),
+			"UnlockRetries":       dbus.MakeVariant(map[uint32]uint32{4: 10, 2: 3}),
+			"State":               dbus.MakeVariant(int32(11)),
+			"StateFailedReason":   dbus.MakeVariant(uint32(0)),
+			"AccessTechnologies":  dbus.MakeVariant(uint32(1 << 14)),
+			"SignalQuality": dbus.MakeVariant(struct {
+				Value  uint32
+				Recent bool
+			}{78, true}),
+			"OwnNumbers": dbus.MakeVariant([]string{"+491234567"}),
+			"PowerState": dbus.MakeVariant(uint32(3)),
+			"SupportedModes": dbus.MakeVariant([]struct {
+				Allowed   uint32
+				Preferred uint32
+			}{{2 | 4 | 8, 8}, {8, 0}}),
+			"CurrentModes": dbus.MakeVariant(struct {
+				Allowed   uint32
+				Preferred uint32
+			}{2 | 4 | 8, 8}),
+			"SupportedBands":      dbus.MakeVariant([]uint32{1, 5, 33, 50, 302}),
+			"CurrentBands":        dbus.MakeVariant([]uint32{33, 50}),
+			"SupportedIpFamilies": dbus.MakeVariant(uint32(1 | 2 | 4)),
+			"MaxBearers":          dbus.MakeVariant(uint32(2)),
+			"SimSlots":            dbus.MakeVariant([]dbus.ObjectPath{simPath, "/"}),
+			"PrimarySimSlot":      dbus.MakeVariant(uint32(1)),
+		},
+		iface3gpp: {
+			"Imei":                 dbus.MakeVariant("123456789012345"),
+			"RegistrationState":    dbus.MakeVariant(uint32(1)),
+			"OperatorCode":         dbus.MakeVariant("26201"),
+			"OperatorName":         dbus.MakeVariant("Telekom.de"),
+			"EnabledFacilityLocks": dbus.MakeVariant(uint32(1)),
+			"EpsUeModeOperation":   dbus.MakeVariant(uint32(3)),
+			"InitialEpsBearer":     dbus.MakeVariant(dbus.ObjectPath("/")),
+			"PacketServiceState":   dbus.MakeVariant(uint32(2)),
+			"Nr5gRegistrationSettings": dbus.MakeVariant(map[string]dbus.Variant{
+				"mico-mode": dbus.MakeVariant(uint32(2)),
+				"drx-cycle": dbus.MakeVariant(uint32(3)),
+			}),
+			"InitialEpsBearerSettings": dbus.MakeVariant(map[string]dbus.Variant{
+				"apn":     dbus.MakeVariant("internet.telekom"),
+				"ip-type": dbus.MakeVariant(uint32(4)),
+			}),
+		},
+		ifaceLocation: {},
+		ifaceSignal:   {},
+	}
+}
+
+func (m *mockModemManager) export(t *testing.T, conn *dbus.Conn) {
+	t.Helper()
+
+	err := conn.ExportMethodTable(map[string]interface{}{
+		"GetManagedObjects": func() (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, *dbus.Error) {
+			return map[dbus.ObjectPath]map[string]map[string]dbus.Variant{modemPath: m.modemInterfaces()}, nil
+		},
+	}, rootPath, ifaceObjectManager)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exports := []struct {
+		path    dbus.ObjectPath
+		iface   string
+		methods map[string]interface{}
+	}{
+		{modemPath, ifaceModem, map[string]interface{}{
+			"Reset": func() *dbus.Error {
+				m.record("Reset")
+				return dbus.NewError("org.freedesktop.ModemManager1.Error.Core.Unauthorized", []interface{}{"Not allowed"})
+			},
+		}},
+		{modemPath, ifaceSimple, map[string]interface{}{
+			"Connect": func(props map[string]dbus.Variant) (dbus.ObjectPath, *dbus.Error) {
+				m.record("Connect apn=" + props["apn"].Value().(string))
+				return "/org/freedesktop/ModemManager1/Bearer/0", nil
+			},
+			"Disconnect": func(bearer dbus.ObjectPath) *dbus.Error {
+				m.record("Disconnect " + string(bearer))
+				return nil
+			},
+		}},
+		{modemPath, ifaceLocation, map[string]interface{}{
+			"GetLocation": func() (map[uint32]dbus.Variant, *dbus.Error) {
+				return map[uint32]dbus.Variant{
+					1: dbus.MakeVariant("262,01,FFFE,01A2B3C4,00D0E1"),
+					2: dbus.MakeVariant(map[string]dbus.Variant{
+						"utc-time":  dbus.MakeVariant("134530.00"),
+						"latitude":  dbus.MakeVariant(52.520008),
+						"longitude": dbus.MakeVariant(13.404954),
+						"altitude":  dbus.MakeVariant(34.5),
+					}),
+					4: dbus.MakeVariant("$GPGGA,134530.00,5231.2005,N\r\n$GPRMC,134530.00,A\r\n"),
+				}, nil
+			},
+			"Setup": func(sources uint32, signal bool) *dbus.Error {
+				m.mu.Lock()
+				m.locationEnabled = sources
+				m.mu.Unlock()
+				return nil
+			},
+		}},
+		{modemPath, ifaceMessaging, map[string]interface{}{
+			"List": func() ([]dbus.ObjectPath, *dbus.Error) {
+				return []dbus.ObjectPath{smsPath}, nil
+			},
+			"Create": func(props map[string]dbus.Variant) (dbus.ObjectPath, *dbus.Error) {
+				m.record("Create text=" + props["text"].Value().(string))
+				return "/org/freedesktop/ModemManager1/SMS/6", nil
+			},
+			"Delete": func(sms dbus.ObjectPath) *dbus.Error {
+				m.record("Delete " + string(sms))
+				return nil
+			},
+		}},
+		{modemPath, ifaceTime, map[string]interface{}{
+			"GetNetworkTime": func() (string, *dbus.Error) {
+				return "2024-05-01T13:45:30+02:00", nil
+			},
+		}},
+		{modemPath, ifaceSignal, map[string]interface{}{
+			"Setup": func(rate uint32) *dbus.Error {
+				m.record(fmt.Sprintf("Setup rate=%d", rate))
+				return nil
+			},
+		}},
+		{"/org/freedesktop/ModemManager1/SMS/6", ifaceSms, map[string]interface{}{
+			"Send": func() *dbus.Error {
+				m.record("Send")
+				return nil
+			},
+		}},
+	}
+	for _, e := range exports {
+		if err := conn.ExportMethodTable(e.methods, e.path, e.iface); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	locationProps := map[string]*prop.Prop{
+		"Capabilities":            {Value: uint32(1 | 2 | 4), Emit: prop.EmitFalse},
+		"SupportedAssistanceData": {Value: uint32(1), Emit: prop.EmitFalse},
+		"Enabled":                 {Value: uint32(1), Emit: prop.EmitFalse},
+		"SignalsLocation":         {Value: true, Emit: prop.EmitFalse},
+		"SuplServer":              {Value: "supl.google.com:7275", Emit: prop.EmitFalse},
+		"AssistanceDataServers":   {Value: []string{"https://xtrapath1.izatcloud.net/xtra3grc.bin"}, Emit: prop.EmitFalse},
+		"GpsRefreshRate":          {Value: uint32(30), Emit: prop.EmitFalse},
+	}
+	messagingProps := map[string]*prop.Prop{
+		"Messages":          {Value: []dbus.ObjectPath{smsPath}, Emit: prop.EmitFalse},
+		"SupportedStorages": {Value: []uint32{1, 2}, Emit: prop.EmitFalse},
+		"DefaultStorage":    {Value: uint32(2), Emit: prop.EmitFalse},
+	}
+	signalProps := map[string]*prop.Prop{
+		"Rate":               {Value: uint32(10), Emit: prop.EmitFalse},
+		"RssiThreshold":      {Value: uint32(0), Emit: prop.EmitFalse},
+		"ErrorRateThreshold": {Value: false, Emit: prop.EmitFalse},
+		"Cdma":               {Value: map[string]dbus.Variant{}, Emit: prop.EmitFalse},
+		"Evdo":               {Value: map[string]dbus.Variant{}, Emit: prop.EmitFalse},
+		"Gsm":                {Value: map[string]dbus.Variant{}, Emit: prop.EmitFalse},
+		"Umts":               {Value: map[string]dbus.Variant{}, Emit: prop.EmitFalse},
+		"Lte": {Value: map[string]dbus.Variant{
+			"rssi": dbus.MakeVariant(-65.0),
+			"rsrp": dbus.MakeVariant(-95.0),
+			"rsrq": dbus.MakeVariant(-11.0),
+			"snr":  dbus.MakeVariant(8.4),
+		}, Emit: prop.EmitFalse},
+		"Nr5g": {Value: map[string]dbus.Variant{
+			"rsrp": dbus.MakeVariant(-88.0),
+			"sinr": dbus.MakeVariant(12.5),
+		}, Emit: prop.EmitFalse},
+	}
+	if _, err := prop.Export(conn, modemPath, prop.Map{ifaceLocation: locationProps, ifaceMessaging: messagingProps, ifaceSignal: signalProps}); err != nil {
+		t.Fatal(err)
+	}
+
+	simProps := map[string]*prop.Prop{
+		"Active":             {Value: true, Emit: prop.EmitFalse},
+		"SimIdentifier":      {Value: "89490200001234567890", Emit: prop.EmitFalse},
+		"Imsi":               {Value: "262011234567890", Emit: prop.EmitFalse},
+		"Eid":                {Value: "", Emit: prop.EmitFalse},
+		"OperatorIdentifier": {Value: "26201", Emit: prop.EmitFalse},
+		"OperatorName":       {Value: "Telekom.de", Emit: prop.EmitFalse},
+		"EmergencyNumbers":   {Value: []string{"112", "110"}, Emit: prop.EmitFalse},
+	}
+	if _, err := prop.Export(conn, simPath, prop.Map{ifaceSim: simProps}); err != nil {
+		t.Fatal(err)
+	}
+
+	smsProps := map[string]*prop.Prop{
+		"State":   {Value: uint32(3), Emit: prop.EmitFalse},
+		"PduType": {Value: uint32(1), Emit: prop.EmitFalse},
+		"Number":  {Value: "+491701234567", Emit: prop.EmitFalse},
+		"Text":    {Value: "Hello scooter", Emit: prop.EmitFalse},
+		"Data":    {Value: []byte{}, Emit: prop.EmitFalse},
+		"SMSC":    {Value: "+491710760000", Emit: prop.EmitFalse},
+		"Validity": {Value: struct {
+			Type  uint32
+			Value dbus.Variant
+		}{0, dbus.MakeVariant(uint32(0))}, Emit: prop.EmitFalse},
+		"Class":                 {Value: int32(-1), Emit: prop.EmitFalse},
+		"TeleserviceId":         {Value: uint32(0), Emit: prop.EmitFalse},
+		"DeliveryReportRequest": {Value: false, Emit: prop.EmitFalse},
+		"Timestamp":             {Value: "2024-05-01T13:40:00+02:00", Emit: prop.EmitFalse},
+		"DischargeTimestamp":    {Value: "", Emit: prop.EmitFalse},
+		"DeliveryState":         {Value: uint32(0x100), Emit: prop.EmitFalse},
+		"Storage":               {Value: uint32(2), Emit: prop.EmitFalse},
+	}
+	if _, err := prop.Export(conn, smsPath, prop.Map{ifaceSms: smsProps}); err != nil {
+		t.Fatal(err)
+	}
+
+	reply, err := conn.RequestName(serviceName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Failed to acquire %s: %v", serviceName, err)
+	}
+}
+
+// newTestBackend starts a private bus with a mocked ModemManager and returns a Backend connected to it
+func newTestBackend(t *testing.T) (*Backend, *mockModemManager) {
+	t.Helper()
+	address := startBus(t)
+
+	serviceConn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Failed to connect to private bus: %v", err)
+	}
+	t.Cleanup(func() { serviceConn.Close() })
+
+	mock := &mockModemManager{}
+	mock.export(t, serviceConn)
+
+	clientConn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Failed to connect to private bus: %v", err)
+	}
+	backend := New(clientConn)
+	t.Cleanup(func() { backend.Close() })
+
+	return backend, mock
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestEnumNicks(t *testing.T) {
+	tests := []struct {
+		table map[uint32]string
+		value uint32
+		nick  string
+	}{
+		{smsPduTypeNicks, 1, "deliver"},
+		{smsPduTypeNicks, 32, "cdma-deliver"},
+		{smsPduTypeNicks, 37, "cdma-read-acknowledgement"},
+		{smsPduTypeNicks, 4, "unknown"},
+	}
+	for _, tt := range tests {
+		if got := nick(tt.table, tt.value); got != tt.nick {
+			t.Errorf("nick(%d) = %q, expected %q", tt.value, got, tt.nick)
+		}
+	}
+}
+
+func TestModemDetails(t *testing.T) {
+	backend, _ := newTestBackend(t)
+	ctx := testContext(t)
+
+	paths, err := backend.ListModems(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list modems: %v", err)
+	}
+	if !reflect.DeepEqual(paths, []string{string(modemPath)}) {
+		t.Errorf("Expected modem list [%s], got %v", modemPath, paths)
+	}
+
+	for _, selector := range []string{"3", "any", string(modemPath), "/sys/devices/platform/soc/usb1/1-1"} {
+		mm, err := backend.GetModemDetails(ctx, selector)
+		if err != nil {
+			t.Fatalf("Failed to get modem details for %q: %v", selector, err)
+		}
+		if mm.Modem.DBusPath != string(modemPath) {
+			t.Errorf("Expected modem %s for selector %q, got %s", modemPath, selector, mm.Modem.DBusPath)
+		}
+	}
+
+	mm, err := backend.GetModemDetails(ctx, "3")
+	if err != nil {
+		t.Fatalf("Failed to get modem details: %v", err)
+	}
+
+	g := mm.Modem.Generic
+	checks := []struct {
+		name     string
+		got      interface{}
+		expected interface{}
+	}{
+		{"state", g.State, "connected"},
+		{"signal", []string{g.SignalQuality.Recent, g.SignalQuality.Value}, []string{"yes", "78"}},
+		{"signal recent", g.SignalQuality.IsRecent, true},
+		{"access technologies", g.AccessTechnologies, []string{"lte"}},
+		{"ports", g.Ports, []string{"cdc-wdm0 (qmi)", "ttyUSB2 (at)", "ttyUSB3 (at)", "wwan0 (net)"}},
+		{"current modes", g.CurrentModes, "allowed: 2g, 3g, 4g; preferred: 4g"},
+		{"supported modes", g.SupportedModes, []string{"allowed: 2g, 3g, 4g; preferred: 4g", "allowed: 4g; preferred: none"}},
+		{"current capabilities", g.CurrentCapabilities, []string{"gsm-umts, lte"}},
+		{"supported bands", g.SupportedBands, []string{"egsm", "utran-1", "eutran-3", "eutran-20", "ngran-2"}},
+		{"unlock required", g.UnlockRequired, "none"},
+		{"unlock retries", g.UnlockRetries, []string{"sim-pin (3)", "sim-puk (10)"}},
+		{"ip families", g.SupportedIPFamilies, []string{"ipv4", "ipv6", "ipv4v6"}},
+		{"power state", g.PowerState, "on"},
+		{"sim", g.SIM, string(simPath)},
+		{"registration", mm.Modem.ThreeGPP.RegistrationState, "home"},
+		{"operator", mm.Modem.ThreeGPP.OperatorName, "Telekom.de"},
+		{"locks", mm.Modem.ThreeGPP.EnabledLocks, []string{"sim"}},
+		{"eps ue mode", mm.Modem.ThreeGPP.EPS.UEModeOperation, "csps-1"},
+		{"max bearers", g.MaxBearers, "2"},
+		{"sim slots", g.SIMSlots, []string{string(simPath), "/"}},
+		{"primary sim slot", g.PrimarySIMSlot, "1"},
+		{"packet service state", mm.Modem.ThreeGPP.PacketServiceState, "attached"},
+		{"5gnr registration settings", mm.Modem.ThreeGPP.FiveGNR.RegistrationSettings, mmcli.NR5GRegistrationSettings{
+			DRXCycle: "64",
+			MICOMode: "disabled",
+		}},
+		{"initial bearer", mm.Modem.ThreeGPP.EPS.InitialBearer, mmcli.EPSBearer{
+			Settings: mmcli.BearerSettings{APN: "internet.telekom", IPType: "ipv4v6"},
+		}},
+	}
+	for _, c := range checks {
+		if !reflect.DeepEqual(c.got, c.expected) {
+			t.Errorf("Expected %s %v, got %v", c.name, c.expected, c.got)
+		}
+	}
+	if p := g.SignalQuality.Percent; p == nil || *p != 78 {
+		t.Errorf("Expected signal percent 78, got %v", p)
+	}
+
+	// The helper methods work on the D-Bus model just like on parsed mmcli output
+	if !mm.IsConnected() {
+		t.Error("Expected modem to be connected")
+	}
+	if tech := mm.GetCurrentAccessTechnology(); tech != "4G" {
+		t.Errorf("Expected 4G technology, got %s", tech)
+	}
+	if retries := mm.RemainingUnlockRetries("sim-pin"); retries != 3 {
+		t.Errorf("Expected 3 PIN retries, got %d", retries)
+	}
+
+	if _, err := backend.GetModemDetails(ctx, "7"); !errors.Is(err, mmcli.ErrModemNotFound) {
+		t.Errorf("Expected ErrModemNotFound, got %v", err)
+	}
+}
+
+func TestSIMAndLocation(t *testing.T) {
+	backend, mock := newTestBackend(t)
+	ctx := testContext(t)
+
+	sim, err := backend.Modem("any").SIM(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get SIM info: %v", err)
+	}
+	if sim.Properties.ICCID != "89490200001234567890" || sim.Properties.Active != "yes" {
+		t.Errorf("Unexpected SIM properties: %+v", sim.Properties)
+	}
+
+	status, err := backend.GetLocationStatus(ctx, "3")
+	if err != nil {
+		t.Fatalf("Failed to get location status: %v", err)
+	}
+	if !reflect.DeepEqual(status.Capabilities, []string{"3gpp-lac-ci", "gps-raw", "gps-nmea"}) {
+		t.Errorf("Unexpected capabilities %v", status.Capabilities)
+	}
+	if status.Signals != "yes" || status.GPS.RefreshRate != "30" {
+		t.Errorf("Unexpected location status %+v", status)
+	}
+
+	location, err := backend.GetLocation(ctx, "3")
+	if err != nil {
+		t.Fatalf("Failed to get location: %v", err)
+	}
+	l := location.ThreeGPP
+	if l.MCC != "262" || l.MNC != "01" || l.LAC != "FFFE" || l.CID != "01A2B3C4" || l.TAC != "00D0E1" {
+		t.Errorf("Unexpected 3GPP location %+v", l)
+	}
+	if l.CIDNumber == nil || *l.CIDNumber != 0x01A2B3C4 || l.TACNumber == nil || *l.TACNumber != 0xD0E1 {
+		t.Errorf("Unexpected typed 3GPP location %+v", l)
+	}
+	if location.GPS.Latitude != "52.520008" || location.GPS.Altitude != "34.5" {
+		t.Errorf("Unexpected GPS location %+v", location.GPS)
+	}
+	if len(location.GPS.NMEA) != 2 {
+		t.Errorf("Expected 2 NMEA traces, got %v", location.GPS.NMEA)
+	}
+
+	if err := backend.EnableLocationGathering(ctx, "3", "gps-nmea"); err != nil {
+		t.Fatalf("Failed to enable location gathering: %v", err)
+	}
+	mock.mu.Lock()
+	enabled := mock.locationEnabled
+	mock.mu.Unlock()
+	if enabled != 1|4 {
+		t.Errorf("Expected sources 3gpp and gps-nmea to be enabled, got %d", enabled)
+	}
+}
+
+func TestSignal(t *testing.T) {
+	backend, mock := newTestBackend(t)
+	ctx := testContext(t)
+
+	if err := backend.SetupSignal(ctx, "3", 10); err != nil {
+		t.Fatalf("Failed to set up signal polling: %v", err)
+	}
+
+	signal, err := backend.GetSignal(ctx, "3")
+	if err != nil {
+		t.Fatalf("Failed to get signal information: %v", err)
+	}
+	if signal.Refresh.Rate != "10" || signal.Threshold.ErrorRate != "no" {
+		t.Errorf("Unexpected signal settings %+v", signal)
+	}
+	if signal.LTE.RSRP != "-95.00" || signal.LTE.SNR != "8.40" || signal.LTE.SINR != "" {
+		t.Errorf("Unexpected LTE values %+v", signal.LTE)
+	}
+	if signal.NR5G.SINR != "12.50" || signal.GSM != (mmcli.SignalValues{}) {
+		t.Errorf("Unexpected signal values %+v", signal)
+	}
+
+	snapshot, err := backend.Modem("3").Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+	if snapshot.Signal.NR5G == nil || snapshot.Signal.NR5G.SINRDB == nil || *snapshot.Signal.NR5G.SINRDB != 12.5 {
+		t.Errorf("Expected 5G SINR in the snapshot, got %+v", snapshot.Signal.NR5G)
+	}
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if !reflect.DeepEqual(mock.calls, []string{"Setup rate=10"}) {
+		t.Errorf("Unexpected calls %v", mock.calls)
+	}
+}
+
+func TestOperations(t *testing.T) {
+	backend, mock := newTestBackend(t)
+	ctx := testContext(t)
+	m := backend.Modem("3")
+
+	if err := m.Connect(ctx, mmcli.ConnectSettings{APN: "internet"}); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if err := m.Disconnect(ctx); err != nil {
+		t.Fatalf("Failed to disconnect: %v", err)
+	}
+
+	err := m.Reset(ctx)
+	if !errors.Is(err, mmcli.ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+
+	if err := m.SendSMS(ctx, "+491701234567", "hi"); err != nil {
+		t.Fatalf("Failed to send SMS: %v", err)
+	}
+
+	list, err := m.SMS(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list SMS: %v", err)
+	}
+	if !reflect.DeepEqual(list, []string{string(smsPath)}) {
+		t.Errorf("Unexpected SMS list %v", list)
+	}
+
+	sms, err := backend.GetSMSInfo(ctx, "5")
+	if err != nil {
+		t.Fatalf("Failed to get SMS info: %v", err)
+	}
+	if sms.Properties.Text != "Hello scooter" || sms.Properties.State != "received" || sms.Properties.Storage != "me" {
+		t.Errorf("Unexpected SMS properties %+v", sms.Properties)
+	}
+
+	status, err := m.MessagingStatus(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get messaging status: %v", err)
+	}
+	if !reflect.DeepEqual(status.SupportedStorages, []string{"sm", "me"}) {
+		t.Errorf("Unexpected supported storages %v", status.SupportedStorages)
+	}
+
+	if err := m.DeleteSMS(ctx, "5"); err != nil {
+		t.Fatalf("Failed to delete SMS: %v", err)
+	}
+
+	networkTime, err := m.NetworkTime(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get network time: %v", err)
+	}
+	if networkTime.UTC().Hour() != 11 {
+		t.Errorf("Unexpected network time %v", networkTime)
+	}
+
+	expected := []string{
+		"Connect apn=internet",
+		"Disconnect /",
+		"Reset",
+		"Create text=hi",
+		"Send",
+		"Delete " + string(smsPath),
+	}
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if !reflect.DeepEqual(mock.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, mock.calls)
+	}
+}
@@ -0,0 +1,272 @@
+package mmdbus
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/rescoot/go-mmcli"
+)
+
+// properties holds the D-Bus properties of one interface. The accessors
+// return zero values for missing properties or unexpected types, since older
+// ModemManager releases do not expose every property.
+type properties map[string]dbus.Variant
+
+func (p properties) value(name string) interface{} {
+	v, ok := p[name]
+	if !ok {
+		return nil
+	}
+	return v.Value()
+}
+
+func (p properties) str(name string) string {
+	s, _ := p.value(name).(string)
+	return s
+}
+
+func (p properties) bool(name string) bool {
+	b, _ := p.value(name).(bool)
+	return b
+}
+
+func (p properties) u32(name string) uint32 {
+	u, _ := p.value(name).(uint32)
+	return u
+}
+
+func (p properties) i32(name string) int32 {
+	i, _ := p.value(name).(int32)
+	return i
+}
+
+func (p properties) strs(name string) []string {
+	s, _ := p.value(name).([]string)
+	return s
+}
+
+func (p properties) u32s(name string) []uint32 {
+	u, _ := p.value(name).([]uint32)
+	return u
+}
+
+func (p properties) path(name string) string {
+	o, _ := p.value(name).(dbus.ObjectPath)
+	return optionalPath(o)
+}
+
+func (p properties) paths(name string) []string {
+	objects, _ := p.value(name).([]dbus.ObjectPath)
+	out := make([]string, 0, len(objects))
+	for _, o := range objects {
+		out = append(out, string(o))
+	}
+	return out
+}
+
+// structs returns an array of structs as slices of their fields
+func (p properties) structs(name string) [][]interface{} {
+	s, _ := p.value(name).([][]interface{})
+	return s
+}
+
+// fields returns a single struct as a slice of its fields
+func (p properties) fields(name string) []interface{} {
+	f, _ := p.value(name).([]interface{})
+	return f
+}
+
+// dict returns an a{sv} dictionary
+func (p properties) dict(name string) properties {
+	d, _ := p.value(name).(map[string]dbus.Variant)
+	return d
+}
+
+// field returns the i-th struct field if it has the expected type
+func field[T any](fields []interface{}, i int) T {
+	var zero T
+	if i >= len(fields) {
+		return zero
+	}
+	v, ok := fields[i].(T)
+	if !ok {
+		return zero
+	}
+	return v
+}
+
+// modemFromProperties fills the mmcli modem model from the modem's D-Bus interfaces
+func modemFromProperties(path dbus.ObjectPath, ifaces map[string]map[string]dbus.Variant) mmcli.Modem {
+	modem := properties(ifaces[ifaceModem])
+	threegpp := properties(ifaces[iface3gpp])
+	cdma := properties(ifaces[ifaceCdma])
+
+	return mmcli.Modem{
+		DBusPath: string(path),
+		Generic:  genericFromProperties(modem),
+		ThreeGPP: threeGPPFromProperties(threegpp),
+		CDMA: mmcli.CDMA{
+			ActivationState:         nickIf(cdma, "ActivationState", cdmaActivationStateNicks),
+			CDMA1xRegistrationState: nickIf(cdma, "Cdma1xRegistrationState", cdmaRegistrationStateNicks),
+			ESN:                     cdma.str("Esn"),
+			EVDORegistrationState:   nickIf(cdma, "EvdoRegistrationState", cdmaRegistrationStateNicks),
+			MEID:                    cdma.str("Meid"),
+			NID:                     uintString(cdma.u32("Nid")),
+			SID:                     uintString(cdma.u32("Sid")),
+		},
+	}
+}
+
+// nickIf returns the nickname of an enum property, or an empty string if the property is missing
+func nickIf(p properties, name string, table map[uint32]string) string {
+	if _, ok := p[name]; !ok {
+		return ""
+	}
+	return nick(table, p.u32(name))
+}
+
+func genericFromProperties(p properties) mmcli.ModemGenericInfo {
+	signal := p.fields("SignalQuality")
+
+	var supportedCapabilities []string
+	for _, caps := range p.u32s("SupportedCapabilities") {
+		supportedCapabilities = append(supportedCapabilities, capabilitiesString(caps))
+	}
+
+	var supportedModes []string
+	for _, mode := range p.structs("SupportedModes") {
+		supportedModes = append(supportedModes, mmcli.ModeCombination{Allowed: mmcli.Mode(field[uint32](mode, 0)), Preferred: mmcli.Mode(field[uint32](mode, 1))}.String())
+	}
+
+	var currentModes string
+	if modes := p.fields("CurrentModes"); modes != nil {
+		currentModes = mmcli.ModeCombination{Allowed: mmcli.Mode(field[uint32](modes, 0)), Preferred: mmcli.Mode(field[uint32](modes, 1))}.String()
+	}
+
+	var ports []string
+	for _, port := range p.structs("Ports") {
+		ports = append(ports, mmcli.Port{Name: field[string](port, 0), Type: mmcli.PortType(field[uint32](port, 1))}.String())
+	}
+
+	var currentCapabilities []string
+	if _, ok := p["CurrentCapabilities"]; ok {
+		currentCapabilities = []string{capabilitiesString(p.u32("CurrentCapabilities"))}
+	}
+
+	retries, _ := p.value("UnlockRetries").(map[uint32]uint32)
+
+	var unlockRequired string
+	if _, ok := p["UnlockRequired"]; ok {
+		unlockRequired = mmcli.Lock(p.u32("UnlockRequired")).String()
+	}
+
+	var stateFailedReason string
+	if _, ok := p["StateFailedReason"]; ok {
+		stateFailedReason = mmcli.StateFailedReason(p.u32("StateFailedReason")).String()
+	}
+
+	return mmcli.ModemGenericInfo{
+		AccessTechnologies:           mmcli.AccessTechnology(p.u32("AccessTechnologies")).Strings(),
+		Bearers:                      p.paths("Bearers"),
+		CarrierConfiguration:         p.str("CarrierConfiguration"),
+		CarrierConfigurationRevision: p.str("CarrierConfigurationRevision"),
+		CurrentBands:                 bandNames(p.u32s("CurrentBands")),
+		CurrentCapabilities:          currentCapabilities,
+		CurrentModes:                 currentModes,
+		Device:                       p.str("Device"),
+		DeviceIdentifier:             p.str("DeviceIdentifier"),
+		Drivers:                      p.strs("Drivers"),
+		EquipmentIdentifier:          p.str("EquipmentIdentifier"),
+		HardwareRevision:             p.str("HardwareRevision"),
+		Manufacturer:                 p.str("Manufacturer"),
+		MaxActiveBearers:             uintString(p.u32("MaxActiveBearers")),
+		MaxActiveMultiplexedBearers:  uintString(p.u32("MaxActiveMultiplexedBearers")),
+		MaxBearers:                   uintString(p.u32("MaxBearers")),
+		Model:                        p.str("Model"),
+		OwnNumbers:                   p.strs("OwnNumbers"),
+		Plugin:                       p.str("Plugin"),
+		Ports:                        ports,
+		PowerState:                   nickIf(p, "PowerState", powerStateNicks),
+		PrimaryPort:                  p.str("PrimaryPort"),
+		PrimarySIMSlot:               uintString(p.u32("PrimarySimSlot")),
+		Revision:                     p.str("Revision"),
+		SignalQuality: mmcli.SignalQuality{
+			Value:  strconv.FormatUint(uint64(field[uint32](signal, 0)), 10),
+			Recent: yesNo(field[bool](signal, 1)),
+		},
+		SIM:                   p.path("Sim"),
+		SIMSlots:              p.paths("SimSlots"),
+		State:                 mmcli.ModemState(p.i32("State")).String(),
+		StateFailedReason:     stateFailedReason,
+		SupportedBands:        bandNames(p.u32s("SupportedBands")),
+		SupportedCapabilities: supportedCapabilities,
+		SupportedIPFamilies:   flagNicks(p.u32("SupportedIpFamilies"), ipFamilyFlags),
+		SupportedModes:        supportedModes,
+		UnlockRequired:        unlockRequired,
+		UnlockRetries:         unlockRetriesStrings(retries),
+	}
+}
+
+func threeGPPFromProperties(p properties) mmcli.ThreeGPP {
+	bearer := p.dict("InitialEpsBearerSettings")
+
+	var ipType string
+	if _, ok := bearer["ip-type"]; ok {
+		ipType = strings.Join(flagNicks(bearer.u32("ip-type"), ipFamilyFlags), ", ")
+	}
+
+	var pco []string
+	for _, entry := range p.structs("Pco") {
+		pco = append(pco, fmt.Sprintf("%d: (%s) %X",
+			field[uint32](entry, 0), completeness(field[bool](entry, 1)), field[[]byte](entry, 2)))
+	}
+	sort.Strings(pco)
+
+	nr5g := p.dict("Nr5gRegistrationSettings")
+
+	return mmcli.ThreeGPP{
+		FiveGNR: mmcli.FiveGNR{
+			RegistrationSettings: mmcli.NR5GRegistrationSettings{
+				DRXCycle: nickIf(nr5g, "drx-cycle", drxCycleNicks),
+				MICOMode: nickIf(nr5g, "mico-mode", micoModeNicks),
+			},
+		},
+		EnabledLocks: flagNicks(p.u32("EnabledFacilityLocks"), facilityLockFlags),
+		EPS: mmcli.EPSInfo{
+			InitialBearer: mmcli.EPSBearer{
+				DBusPath: p.path("InitialEpsBearer"),
+				Settings: mmcli.BearerSettings{
+					APN:      bearer.str("apn"),
+					IPType:   ipType,
+					Password: bearer.str("password"),
+					User:     bearer.str("user"),
+				},
+			},
+			UEModeOperation: nickIf(p, "EpsUeModeOperation", epsUeModeNicks),
+		},
+		IMEI:               p.str("Imei"),
+		OperatorCode:       p.str("OperatorCode"),
+		OperatorName:       p.str("OperatorName"),
+		PacketServiceState: nickIf(p, "PacketServiceState", packetServiceStateNicks),
+		PCO:                strings.Join(pco, "\n"),
+		RegistrationState:  nickIf(p, "RegistrationState", registrationStateNicks),
+	}
+}
+
+func completeness(complete bool) string {
+	if complete {
+		return "complete"
+	}
+	return "partial"
+}
+
+func bandNames(bands []uint32) []string {
+	var names []string
+	for _, band := range bands {
+		names = append(names, mmcli.Band(band).String())
+	}
+	return names
+}
@@ -0,0 +1,73 @@
+package mmdbus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/rescoot/go-mmcli"
+)
+
+// GetSignal returns the extended signal information. The modem only reports
+// values after polling was enabled with SetupSignal.
+func (b *Backend) GetSignal(ctx context.Context, modemID string) (*mmcli.SignalInfo, error) {
+	path, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal information: %w", err)
+	}
+
+	props, err := b.getAll(ctx, path, ifaceSignal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal information: %w", err)
+	}
+
+	return &mmcli.SignalInfo{
+		Refresh: mmcli.SignalRefresh{Rate: strconv.FormatUint(uint64(props.u32("Rate")), 10)},
+		Threshold: mmcli.SignalThreshold{
+			RSSI:      strconv.FormatUint(uint64(props.u32("RssiThreshold")), 10),
+			ErrorRate: yesNo(props.bool("ErrorRateThreshold")),
+		},
+		CDMA1x: signalValues(props.dict("Cdma")),
+		EVDO:   signalValues(props.dict("Evdo")),
+		GSM:    signalValues(props.dict("Gsm")),
+		UMTS:   signalValues(props.dict("Umts")),
+		LTE:    signalValues(props.dict("Lte")),
+		NR5G:   signalValues(props.dict("Nr5g")),
+	}, nil
+}
+
+// SetupSignal enables polling the extended signal information every rate
+// seconds. A rate of 0 disables polling.
+func (b *Backend) SetupSignal(ctx context.Context, modemID string, rate int) error {
+	path, _, err := b.findModem(ctx, modemID)
+	if err != nil {
+		return fmt.Errorf("failed to set up signal polling: %w", err)
+	}
+	if err := b.call(ctx, path, ifaceSignal+".Setup", uint32(rate)).Err; err != nil {
+		return fmt.Errorf("failed to set up signal polling: %w", convertError(err))
+	}
+	return nil
+}
+
+// signalValues formats the values of one access technology the way mmcli
+// prints them. Values the modem does not report are left empty.
+func signalValues(p properties) mmcli.SignalValues {
+	value := func(name string) string {
+		v, ok := p.value(name).(float64)
+		if !ok {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	return mmcli.SignalValues{
+		ECIO:      value("ecio"),
+		ErrorRate: value("error-rate"),
+		IO:        value("io"),
+		RSCP:      value("rscp"),
+		RSRP:      value("rsrp"),
+		RSRQ:      value("rsrq"),
+		RSSI:      value("rssi"),
+		SINR:      value("sinr"),
+		SNR:       value("snr"),
+	}
+}